@@ -1,59 +1,88 @@
 package astquery
 
 import (
-	"fmt"
 	"github.com/robertkrimen/otto/ast"
 )
 
 // assignQuery requires the current expression to be assign
-type assignQuery struct {
-	assign ast.Expression
-}
+type assignQuery struct{}
 
-func (qo *assignQuery) run(e ast.Expression) error {
-	var isAssign bool
-	qo.assign, isAssign = e.(*ast.AssignExpression)
-	if !isAssign {
-		return fmt.Errorf("Expression is not assign, was %T", e)
+func (qo *assignQuery) run(s *state, e ast.Node) (ast.Node, Captures, error) {
+	if _, isAssign := e.(*ast.AssignExpression); !isAssign {
+		return nil, nil, mismatch(e, "AssignExpression", "Expression is not assign, was %T", e)
 	}
 
-	return nil
-}
-
-func (qo *assignQuery) get() ast.Expression {
-	return qo.assign
+	return e, nil, nil
 }
 
 // MustBeAssign restricts the expression to be binary
 func (q *Query) MustBeAssign() *Query {
-	q.operations = append(q.operations, &assignQuery{})
+	q.add("MustBeAssign", &assignQuery{})
 	return q
 }
 
 
-type assignOrVarQuery struct {
-	assignOrVar ast.Expression
+type assignOrVarQuery struct{}
+
+func (qo *assignOrVarQuery) run(s *state, e ast.Node) (ast.Node, Captures, error) {
+	switch e.(type) {
+	case *ast.AssignExpression, *ast.VariableExpression:
+		return e, nil, nil
+	default:
+		return nil, nil, mismatch(e, "", "Expression is not a variable or assign expression, was %T", e)
+	}
 }
 
-func (qo *assignOrVarQuery) run(e ast.Expression) error {
-	var isAssign, isVar bool
-	qo.assignOrVar, isAssign = e.(*ast.AssignExpression)
-	if !isAssign {
-		qo.assignOrVar, isVar = e.(*ast.VariableExpression)
-		if !isVar {
-			return fmt.Errorf("Expression is not a variable or assign expression, was %T", e)
-		}
+// MustBeAssign restricts the expression to be binary
+func (q *Query) MustBeAssignOrVar() *Query {
+	q.add("MustBeAssignOrVar", &assignOrVarQuery{})
+	return q
+}
+
+// variableIdentifier returns the name of a variable as an identifier. The variable expression does not
+// hold an identifier node, so the identifier is not part of the tree.
+func variableIdentifier(v *ast.VariableExpression) *ast.Identifier {
+	return &ast.Identifier{
+		Name: v.Name,
+		Idx:  v.Idx,
 	}
+}
 
-	return nil
+// assignTargetQuery continues from the target of an assignment
+type assignTargetQuery struct{}
+
+func (qo *assignTargetQuery) run(s *state, e ast.Node) (ast.Node, Captures, error) {
+	switch t := e.(type) {
+	case *ast.AssignExpression:
+		return t.Left, nil, nil
+	case *ast.VariableExpression:
+		return variableIdentifier(t), nil, nil
+	default:
+		return nil, nil, mismatch(e, "", "Expression is not a variable or assign expression, was %T", e)
+	}
 }
 
-func (qo *assignOrVarQuery) get() ast.Expression {
-	return qo.assignOrVar
+// AssignTarget continues from the target of an assign expression, e.g. window.a for window.a = 1,
+// or the name of a variable expression as an identifier
+func (q *Query) AssignTarget() *Query {
+	q.add("AssignTarget", &assignTargetQuery{})
+	return q
 }
 
-// MustBeAssign restricts the expression to be binary
-func (q *Query) MustBeAssignOrVar() *Query {
-	q.operations = append(q.operations, &assignOrVarQuery{})
+// variableNameQuery continues from the name of a variable
+type variableNameQuery struct{}
+
+func (qo *variableNameQuery) run(s *state, e ast.Node) (ast.Node, Captures, error) {
+	v, isVar := e.(*ast.VariableExpression)
+	if !isVar {
+		return nil, nil, mismatch(e, "VariableExpression", "Expression is not a variable expression, was %T", e)
+	}
+
+	return variableIdentifier(v), nil, nil
+}
+
+// VariableName continues from the name of a variable expression as an identifier
+func (q *Query) VariableName() *Query {
+	q.add("VariableName", &variableNameQuery{})
 	return q
 }
@@ -0,0 +1,46 @@
+package astquery
+
+import (
+	"testing"
+)
+
+func TestLeftSide(t *testing.T) {
+	tests := []struct {
+		src     string
+		query   *Query
+		matches int
+	}{
+		// Test 0
+		{`window.a = 1; window["b"] = 2; self.c = 3;`, NewQuery().MustBeAssign().LeftSide(NewQuery().MemberPath("window.*")), 2},
+
+		// Test 1
+		{`"a" + b; a + "b";`, NewQuery().MustBeBinary().LeftSide(MustCompile("StringLiteral")), 1},
+
+		// Test 2
+		{`var _ = 1, a = 2;`, NewQuery().LeftSide(NewQuery().NameIs("_")), 1},
+
+		// Test 3
+		{`window.a = 1; self.c = 3;`, NewQuery().AssignTarget().MemberPath("window.*"), 1},
+
+		// Test 4
+		{`var _ = 1, a; _ = 2;`, NewQuery().AssignTarget().NameIs("_"), 2},
+
+		// Test 5
+		{`var _ = 1, a; _ = 2;`, NewQuery().VariableName().NameIs("_"), 1},
+
+		// Test 6
+		{`f();`, NewQuery().LeftSide(NewQuery()), 0},
+	}
+
+	for i, test := range tests {
+		matches, err := QuerySource(test.src, test.query)
+		if err != nil {
+			t.Errorf("Test %v failed, %v", i, err)
+			continue
+		}
+
+		if len(matches) != test.matches {
+			t.Errorf("Test %v should have %v matches, got %v", i, test.matches, len(matches))
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package astquery
+
+import (
+	"github.com/robertkrimen/otto/ast"
+)
+
+// parents maps nodes to their parent node
+type parents map[ast.Node]ast.Node
+
+// newParents returns the parents of every node below root.
+func newParents(root ast.Node) parents {
+	p := parents{}
+	walk(root, func(node ast.Node, path []ast.Node) bool {
+		if len(path) > 0 {
+			p[node] = path[len(path)-1]
+		}
+		return true
+	})
+
+	return p
+}
+
+// axis determines the nodes an axis query is run on, relative to the current expression
+type axis int
+
+const (
+	axisChild axis = iota
+	axisDescendant
+	axisParent
+	axisAncestor
+)
+
+func (a axis) String() string {
+	switch a {
+	case axisChild:
+		return "child"
+	case axisDescendant:
+		return "descendant"
+	case axisParent:
+		return "parent"
+	default:
+		return "ancestor"
+	}
+}
+
+// axisQuery runs a query on the expressions along an axis of the current expression until one matches.
+type axisQuery struct {
+	axis  axis
+	query *Query
+}
+
+func (qo *axisQuery) run(s *state, e ast.Node) (ast.Node, Captures, error) {
+	for _, node := range qo.nodes(s.parents, e) {
+		if result, err := s.sub(qo.query, node); err == nil {
+			return e, result.Captures, nil
+		}
+	}
+
+	return nil, nil, mismatch(e, "", "No %v of %T matched", qo.axis, e)
+}
+
+// nodes returns the nodes along the axis, nearest first.
+func (qo *axisQuery) nodes(p parents, e ast.Node) []ast.Node {
+	switch qo.axis {
+	case axisChild:
+		return children(e)
+
+	case axisDescendant:
+		var nodes []ast.Node
+		walk(e, func(node ast.Node, path []ast.Node) bool {
+			if len(path) > 0 {
+				nodes = append(nodes, node)
+			}
+			return true
+		})
+		return nodes
+
+	case axisParent:
+		if parent, hasParent := p[e]; hasParent {
+			return []ast.Node{parent}
+		}
+		return nil
+
+	default:
+		var nodes []ast.Node
+		for node, hasParent := p[e]; hasParent; node, hasParent = p[node] {
+			nodes = append(nodes, node)
+		}
+		return nodes
+	}
+}
+
+// Has will only pass if the query matches an expression below the current expression.
+func (q *Query) Has(query *Query) *Query {
+	q.add("Has", &axisQuery{
+		axis:  axisDescendant,
+		query: query,
+	})
+	return q
+}
+
+// Child will only pass if the query matches a direct child of the current expression.
+func (q *Query) Child(query *Query) *Query {
+	q.add("Child", &axisQuery{
+		axis:  axisChild,
+		query: query,
+	})
+	return q
+}
+
+// Inside will only pass if the query matches an expression above the current expression.
+// Ancestors are only known when the query is run on a program, e.g. by RunProgram.
+func (q *Query) Inside(query *Query) *Query {
+	q.add("Inside", &axisQuery{
+		axis:  axisAncestor,
+		query: query,
+	})
+	return q
+}
+
+// Parent will only pass if the query matches the parent of the current expression.
+// Parents are only known when the query is run on a program, e.g. by RunProgram.
+func (q *Query) Parent(query *Query) *Query {
+	q.add("Parent", &axisQuery{
+		axis:  axisParent,
+		query: query,
+	})
+	return q
+}
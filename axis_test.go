@@ -0,0 +1,72 @@
+package astquery
+
+import (
+	"testing"
+
+	"github.com/robertkrimen/otto/ast"
+)
+
+func TestAxes(t *testing.T) {
+	tests := []struct {
+		src     string
+		query   *Query
+		matches int
+	}{
+		// Test 0
+		{`a = f(); b = 1 + g(); c = 2;`, NewQuery().MustBeAssign().Has(NewQuery().MustBeCall()), 2},
+
+		// Test 1
+		{`a = f(); b = 1 + g(); c = 2;`, NewQuery().MustBeAssign().Child(NewQuery().MustBeCall()), 1},
+
+		// Test 2
+		{`a = {b: 1}; c = [{d: 2}];`, NewQuery().Has(NewQuery().MustBeObjectLiteral().Inside(MustCompile("ArrayLiteral"))), 4},
+
+		// Test 3
+		{`a = f(); b = [g()];`, NewQuery().Has(NewQuery().MustBeCall().Parent(NewQuery().MustBeAssign())), 3},
+
+		// Test 4
+		{`a = function() { return f(); }; b = g();`, NewQuery().MustBeCall().Inside(NewQuery().MustBeFunctionLiteral()), 1},
+
+		// Test 5
+		{`a = function() { return f(); }; g();`, NewQuery().MustBeCall().Not(NewQuery().Inside(NewQuery().MustBeFunctionLiteral())), 1},
+	}
+
+	for i, test := range tests {
+		matches, err := QuerySource(test.src, test.query)
+		if err != nil {
+			t.Errorf("Test %v failed, %v", i, err)
+			continue
+		}
+
+		if len(matches) != test.matches {
+			t.Errorf("Test %v should have %v matches, got %v", i, test.matches, len(matches))
+		}
+	}
+}
+
+func TestHas_captures(t *testing.T) {
+	matches, err := QuerySource(`a = 1 + f();`, NewQuery().MustBeAssign().Has(NewQuery().MustBeCall().Capture("call")))
+	if err != nil {
+		t.Fatalf("Test failed, %v", err)
+	}
+
+	if len(matches) != 1 {
+		t.Fatalf("Expected 1 match, got %v", len(matches))
+	}
+	if _, isAssign := matches[0].Result.(*ast.AssignExpression); !isAssign {
+		t.Errorf("Has should not navigate, result was %T", matches[0].Result)
+	}
+	if _, isCall := matches[0].Capture("call").(*ast.CallExpression); !isCall {
+		t.Errorf("Capture call not correct, was %v", matches[0].Capture("call"))
+	}
+}
+
+func TestInside_without_program(t *testing.T) {
+	call := &ast.CallExpression{Callee: &ast.Identifier{Name: "f"}}
+	assign := &ast.AssignExpression{Left: &ast.Identifier{Name: "a"}, Right: call}
+
+	err := NewQuery().Child(NewQuery().Parent(NewQuery().MustBeAssign())).Run(assign)
+	if err == nil {
+		t.Errorf("Test should have failed, parents are unknown")
+	}
+}
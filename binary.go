@@ -1,35 +1,27 @@
 package astquery
 
 import (
-	"fmt"
 	"github.com/robertkrimen/otto/ast"
 )
 
 // binaryQuery requires the current expression to be binary
 type binaryQuery struct {
-	binary ast.Expression
 }
 
-func (qo *binaryQuery) run(e ast.Expression) error {
+func (qo *binaryQuery) run(s *state, e ast.Node) (ast.Node, Captures, error) {
 	switch e.(type) {
 	case *ast.AssignExpression:
-		qo.binary = e
 	case *ast.BinaryExpression:
-		qo.binary = e
 	default:
-		return fmt.Errorf("Expression is not binary, was %T", e)
+		return nil, nil, mismatch(e, "BinaryExpression", "Expression is not binary, was %T", e)
 	}
 
-	return nil
-}
-
-func (qo *binaryQuery) get() ast.Expression {
-	return qo.binary
+	return e, nil, nil
 }
 
 // MustBeBinary restricts the expression to be binary
 func (q *Query) MustBeBinary() *Query {
-	q.operations = append(q.operations, &binaryQuery{})
+	q.add("MustBeBinary", &binaryQuery{})
 	return q
 }
 
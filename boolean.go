@@ -1,33 +1,27 @@
 package astquery
 
 import (
-	"fmt"
 	"github.com/robertkrimen/otto/ast"
 )
 
 // booleanQuery will determine if a part of the tree is solely booleans
 type booleanQuery struct {
-	depth      int
-	expression ast.Expression
+	depth int
 }
 
-func (qo *booleanQuery) run(expression ast.Expression) error {
-	qo.expression = expression
-	ok := VerifyExpression(expression, qo.depth, newOnlyBooleanVerifier())
+func (qo *booleanQuery) run(s *state, expression ast.Node) (ast.Node, Captures, error) {
+	e, isExpression := expression.(ast.Expression)
+	ok := isExpression && VerifyExpression(e, qo.depth, newOnlyBooleanVerifier())
 	if !ok {
-		return fmt.Errorf("Expression does not only contain booleans")
+		return nil, nil, mismatch(expression, "", "Expression does not only contain booleans")
 	}
 
-	return nil
-}
-
-func (qo *booleanQuery) get() ast.Expression {
-	return qo.expression
+	return expression, nil, nil
 }
 
 // AcceptBoolean will only pass if the subtree is solely booleans.
 func (q *Query) AcceptBoolean(depth int) *Query {
-	q.operations = append(q.operations, &booleanQuery{
+	q.add("AcceptBoolean", &booleanQuery{
 		depth: depth,
 	})
 	return q
@@ -1,93 +1,258 @@
 package astquery
 
 import (
-	"fmt"
 	"github.com/robertkrimen/otto/ast"
 )
 
 // callQuery
 type callQuery struct {
-	depth int
-	first bool
-	call  *ast.CallExpression
-	newe  *ast.NewExpression
+	depth     int
+	first     bool
+	anonymous bool
 }
 
-func (qo *callQuery) run(e ast.Expression) error {
+func (qo *callQuery) run(s *state, e ast.Node) (ast.Node, Captures, error) {
 	if qo.depth > 0 {
 		inspector := &CallInspector{}
 		inspector.First = qo.first
 		Inspect(e, inspector)
-		ok := inspector.Call != nil || inspector.New != nil
-		if !ok {
-			return fmt.Errorf("Expression does not contain a call")
+		if inspector.Call != nil {
+			return inspector.Call, nil, nil
 		}
-		qo.call = inspector.Call
-		qo.newe = inspector.New
-	} else {
-		var isCall bool
-		qo.call, isCall = e.(*ast.CallExpression)
-		if !isCall {
-			return fmt.Errorf("Expression is not call, was %T", e)
+		if inspector.New != nil {
+			return inspector.New, nil, nil
 		}
+
+		return nil, nil, mismatch(e, "", "Expression does not contain a call")
 	}
 
-	return nil
+	call, isCall := e.(*ast.CallExpression)
+	if !isCall {
+		return nil, nil, mismatch(e, "CallExpression", "Expression is not call, was %T", e)
+	}
+	if qo.anonymous && !isFunctionCallee(call.Callee) {
+		return nil, nil, mismatch(e, "", "Callee is not a function literal, was %T", call.Callee)
+	}
+
+	return call, nil, nil
 }
 
-func (qo *callQuery) get() ast.Expression {
-	if qo.call != nil {
-		return qo.call
+// isFunctionCallee determines if a callee is a function literal, either directly or through call or apply.
+func isFunctionCallee(callee ast.Expression) bool {
+	if dot, isDot := callee.(*ast.DotExpression); isDot {
+		if dot.Identifier.Name != "call" && dot.Identifier.Name != "apply" {
+			return false
+		}
+		callee = dot.Left
 	}
 
-	return qo.newe
+	_, isFunction := callee.(*ast.FunctionLiteral)
+	return isFunction
 }
 
+// MustBeAnonymousCall restricts the expression to be an immediately invoked function expression,
+// a call whose callee is a function literal, e.g. (function() {})() or (function() {}).call(this)
 func (q *Query) MustBeAnonymousCall() *Query {
-	q.operations = append(q.operations, &callQuery{})
+	q.add("MustBeAnonymousCall", &callQuery{
+		anonymous: true,
+	})
 	return q
 }
 
 // MustBeCall restricts the expression to be a call
 func (q *Query) MustBeCall() *Query {
-	q.operations = append(q.operations, &callQuery{})
+	q.add("MustBeCall", &callQuery{})
 	return q
 }
 
 func (q *Query) MustBeCallD(first bool) *Query {
-	q.operations = append(q.operations, &callQuery{1, first, nil, nil})
+	q.add("MustBeCallD", &callQuery{depth: 1, first: first})
 	return q
 }
 
 // Callee name
 type calleeName struct {
-	identifier *ast.Identifier
+	constructor bool
 }
 
-func (qo *calleeName) run(e ast.Expression) error {
-	call, isCall := e.(*ast.CallExpression)
-	if !isCall {
-		return fmt.Errorf("Expression is not a call")
+func (qo *calleeName) run(s *state, e ast.Node) (ast.Node, Captures, error) {
+	var callee ast.Expression
+	if qo.constructor {
+		newe, isNew := e.(*ast.NewExpression)
+		if !isNew {
+			return nil, nil, mismatch(e, "NewExpression", "Expression is not a new expression, was %T", e)
+		}
+		callee = newe.Callee
+	} else {
+		call, isCall := e.(*ast.CallExpression)
+		if !isCall {
+			return nil, nil, mismatch(e, "CallExpression", "Expression is not a call, was %T", e)
+		}
+		callee = call.Callee
 	}
 
-	switch t := call.Callee.(type) {
+	switch t := callee.(type) {
 	case *ast.Identifier:
-		qo.identifier = t
+		return t, nil, nil
 	case *ast.DotExpression:
-		qo.identifier = t.Identifier
+		return t.Identifier, nil, nil
 
 	default:
-		return fmt.Errorf("Call expression does not contain an identifier")
+		return nil, nil, mismatch(e, "", "Call expression does not contain an identifier")
 	}
+}
 
-	return nil
+func (q *Query) CallMustHaveIdentifier() *Query {
+	q.add("CallMustHaveIdentifier", &calleeName{})
+	return q
 }
 
-func (qo *calleeName) get() ast.Expression {
-	return qo.identifier
+// NewCalleeName requires the callee of the new expression to be an identifier, or a dot expression,
+// and continues from the identifier, e.g. Foo for new Foo() and new a.Foo()
+func (q *Query) NewCalleeName() *Query {
+	q.add("NewCalleeName", &calleeName{
+		constructor: true,
+	})
+	return q
 }
 
-func (q *Query) CallMustHaveIdentifier() *Query {
-	q.operations = append(q.operations, &calleeName{})
+// newQuery requires the current expression to be a new expression
+type newQuery struct {
+}
+
+func (qo *newQuery) run(s *state, e ast.Node) (ast.Node, Captures, error) {
+	if _, isNew := e.(*ast.NewExpression); !isNew {
+		return nil, nil, mismatch(e, "NewExpression", "Expression is not new, was %T", e)
+	}
+
+	return e, nil, nil
+}
+
+// MustBeNew restricts the expression to be a constructor call
+func (q *Query) MustBeNew() *Query {
+	q.add("MustBeNew", &newQuery{})
+	return q
+}
+
+// callArguments returns the arguments of a call or new expression.
+func callArguments(e ast.Node) ([]ast.Expression, error) {
+	switch t := e.(type) {
+	case *ast.CallExpression:
+		return t.ArgumentList, nil
+	case *ast.NewExpression:
+		return t.ArgumentList, nil
+	default:
+		return nil, mismatch(e, "", "Expression is not a call, was %T", e)
+	}
+}
+
+// argumentsQuery runs queries on the arguments of a call or new expression
+type argumentsQuery struct {
+	// queries run on the arguments starting at offset, a nil query matches any argument
+	queries []*Query
+	offset  int
+
+	// exact requires exactly the arguments matched by queries
+	exact bool
+
+	// rest runs on every argument after the ones matched by queries
+	rest *Query
+}
+
+func (qo *argumentsQuery) run(s *state, e ast.Node) (ast.Node, Captures, error) {
+	arguments, err := callArguments(e)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if qo.offset < 0 {
+		return nil, nil, mismatch(e, "", "Invalid argument index %v", qo.offset)
+	}
+
+	end := qo.offset + len(qo.queries)
+	if len(arguments) < end || (qo.exact && len(arguments) != end) {
+		return nil, nil, mismatch(e, "", "Call has %v arguments", len(arguments))
+	}
+
+	var captures Captures
+	for i, q := range qo.queries {
+		if q == nil {
+			continue
+		}
+		result, err := s.sub(q, arguments[qo.offset+i])
+		if err != nil {
+			return nil, nil, mismatch(e, "", "Argument %v did not match", qo.offset+i).because(err)
+		}
+		captures = captures.merge(result.Captures)
+	}
+
+	if qo.rest != nil {
+		for i := end; i < len(arguments); i++ {
+			result, err := s.sub(qo.rest, arguments[i])
+			if err != nil {
+				return nil, nil, mismatch(e, "", "Argument %v did not match", i).because(err)
+			}
+			captures = captures.merge(result.Captures)
+		}
+	}
+
+	return e, captures, nil
+}
+
+// Arg will only pass if the call or new expression has an argument at index matching the query.
+// A negative index never passes.
+func (q *Query) Arg(index int, query *Query) *Query {
+	q.add("Arg", &argumentsQuery{
+		queries: []*Query{query},
+		offset:  index,
+	})
+	return q
+}
+
+// Args will only pass if the call or new expression has exactly one argument per query, each matching
+// the query at the same position. A nil query matches any argument.
+func (q *Query) Args(queries ...*Query) *Query {
+	q.add("Args", &argumentsQuery{
+		queries: queries,
+		exact:   true,
+	})
+	return q
+}
+
+// RestArgs will only pass if every argument of the call or new expression, from index and on, matches the query.
+// A negative index never passes.
+func (q *Query) RestArgs(from int, query *Query) *Query {
+	q.add("RestArgs", &argumentsQuery{
+		offset: from,
+		rest:   query,
+	})
+	return q
+}
+
+// argCountQuery requires the number of arguments of a call or new expression to be within a range
+type argCountQuery struct {
+	min, max int
+}
+
+func (qo *argCountQuery) run(s *state, e ast.Node) (ast.Node, Captures, error) {
+	arguments, err := callArguments(e)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(arguments) < qo.min || (qo.max >= 0 && len(arguments) > qo.max) {
+		return nil, nil, mismatch(e, "", "Invalid number of arguments for call, %v", len(arguments))
+	}
+
+	return e, nil, nil
+}
+
+// ArgCount will only pass if the call or new expression has between min and max arguments, both inclusive.
+// A negative max means no upper bound.
+func (q *Query) ArgCount(min, max int) *Query {
+	q.add("ArgCount", &argCountQuery{
+		min: min,
+		max: max,
+	})
 	return q
 }
@@ -0,0 +1,104 @@
+package astquery
+
+import (
+	"testing"
+)
+
+func TestCallArguments(t *testing.T) {
+	tests := []struct {
+		src     string
+		query   *Query
+		matches int
+	}{
+		// Test 0
+		{`setTimeout("a()", 1); setTimeout(f, 1);`, NewQuery().CalleePath("setTimeout").Arg(0, MustCompile("StringLiteral")), 1},
+
+		// Test 1
+		{`f(); f(1); f(1, 2); f(1, 2, 3);`, NewQuery().ArgCount(1, 2), 2},
+
+		// Test 2
+		{`f(); f(1); f(1, 2); f(1, 2, 3);`, NewQuery().ArgCount(2, -1), 2},
+
+		// Test 3
+		{`new F(1); new F(); new F("a");`, NewQuery().Arg(0, NewQuery().AcceptNumbers(1)), 1},
+
+		// Test 4
+		{`f(1, "a"); f(1, 2); f(1, "a", 3);`, NewQuery().Args(nil, MustCompile("StringLiteral")), 1},
+
+		// Test 5
+		{`f(a, 1, 2); f(a, 1, "b"); f(a);`, NewQuery().Arg(0, MustCompile("Identifier")).RestArgs(1, NewQuery().AcceptNumbers(1)), 2},
+
+		// Test 6
+		{`f(1); a + b;`, NewQuery().Arg(1, NewQuery()), 0},
+
+		// Test 7, negative indexes never match
+		{`f(1, 2);`, NewQuery().Arg(-1, NewQuery()), 0},
+
+		// Test 8
+		{`f(1, 2);`, NewQuery().RestArgs(-1, NewQuery()), 0},
+	}
+
+	for i, test := range tests {
+		matches, err := QuerySource(test.src, test.query)
+		if err != nil {
+			t.Errorf("Test %v failed, %v", i, err)
+			continue
+		}
+
+		if len(matches) != test.matches {
+			t.Errorf("Test %v should have %v matches, got %v", i, test.matches, len(matches))
+		}
+	}
+}
+
+func TestArg_captures(t *testing.T) {
+	matches, err := QuerySource(`f(a, "b");`, NewQuery().MustBeCall().Capture("call").Arg(0, NewQuery().Capture("first")))
+	if err != nil {
+		t.Fatalf("Test failed, %v", err)
+	}
+
+	if len(matches) != 1 {
+		t.Fatalf("Expected 1 match, got %v", len(matches))
+	}
+	if matches[0].Capture("call") == nil || matches[0].Capture("first") == nil {
+		t.Errorf("Both call and first argument should be captured, was %v", matches[0].Captures)
+	}
+}
+
+func TestCallKinds(t *testing.T) {
+	tests := []struct {
+		src     string
+		query   *Query
+		matches int
+	}{
+		// Test 0
+		{`(function() {})(); f(); new F();`, NewQuery().MustBeAnonymousCall(), 1},
+
+		// Test 1
+		{`(function() {}).call(this); (function() {}).bind(this); f.call(this);`, NewQuery().MustBeAnonymousCall(), 1},
+
+		// Test 2
+		{`(function() {})(); f(); new F();`, NewQuery().MustBeCall(), 2},
+
+		// Test 3
+		{`new Foo(); new a.Foo(); new Bar(); Foo();`, NewQuery().MustBeNew().NewCalleeName().NameIs("Foo"), 2},
+
+		// Test 4
+		{`new (f())(); new Foo();`, NewQuery().NewCalleeName(), 1},
+
+		// Test 5
+		{`new Foo(); Foo();`, NewQuery().CallMustHaveIdentifier(), 1},
+	}
+
+	for i, test := range tests {
+		matches, err := QuerySource(test.src, test.query)
+		if err != nil {
+			t.Errorf("Test %v failed, %v", i, err)
+			continue
+		}
+
+		if len(matches) != test.matches {
+			t.Errorf("Test %v should have %v matches, got %v", i, test.matches, len(matches))
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package astquery
+
+import "github.com/robertkrimen/otto/ast"
+
+// Captures holds the expressions captured by name while running a query
+type Captures map[string]ast.Node
+
+// merge adds all captures from other, overwriting existing names.
+func (c Captures) merge(other Captures) Captures {
+	if len(other) == 0 {
+		return c
+	}
+
+	if c == nil {
+		c = Captures{}
+	}
+	for name, e := range other {
+		c[name] = e
+	}
+
+	return c
+}
+
+// captureQuery captures the current expression by name
+type captureQuery struct {
+	name string
+}
+
+func (qo *captureQuery) run(s *state, e ast.Node) (ast.Node, Captures, error) {
+	return e, Captures{qo.name: e}, nil
+}
+
+// Capture captures the current expression under the given name.
+// Captures in sub queries are available from the query using them.
+func (q *Query) Capture(name string) *Query {
+	q.add("Capture", &captureQuery{
+		name: name,
+	})
+	return q
+}
+
+// Captured returns the expression captured by name in the last run, or nil.
+func (ql *Query) Captured(name string) ast.Node {
+	return ql.captures[name]
+}
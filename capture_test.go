@@ -0,0 +1,66 @@
+package astquery
+
+import (
+	"testing"
+
+	"github.com/robertkrimen/otto/ast"
+	"github.com/robertkrimen/otto/token"
+)
+
+func TestQuery_Capture(t *testing.T) {
+	program := parseProgram(t, `var a = f(1);`)
+
+	matches := NewQuery().MustBeAssignOrVar().Capture("var").RightSide(NewQuery().MustBeCall().Capture("call")).RunProgram(program)
+	if len(matches) != 1 {
+		t.Fatalf("Expected 1 match, got %v", len(matches))
+	}
+
+	if v, isVar := matches[0].Capture("var").(*ast.VariableExpression); !isVar || v.Name != "a" {
+		t.Errorf("Capture var not correct, was %v", matches[0].Capture("var"))
+	}
+	if _, isCall := matches[0].Capture("call").(*ast.CallExpression); !isCall {
+		t.Errorf("Capture call not correct, was %v", matches[0].Capture("call"))
+	}
+}
+
+func TestQuery_Capture_sub_queries(t *testing.T) {
+	left := &ast.BooleanLiteral{Value: true, Literal: "true"}
+	right := &ast.NumberLiteral{Value: 1, Literal: "1"}
+	binary := &ast.BinaryExpression{
+		Operator: token.LOGICAL_OR,
+		Left:     left,
+		Right:    right,
+	}
+
+	q := NewQuery().MustBeBinary().OneSideOtherSide(
+		NewQuery().AcceptNumbers(1).Capture("number"),
+		NewQuery().Either(NewQuery().MustBeUnary().Capture("unary"), NewQuery().AcceptBoolean(1).Capture("boolean")))
+
+	err := q.Run(binary)
+	if err != nil {
+		t.Fatalf("Test failed, %v", err)
+	}
+
+	if q.Captured("number") != right {
+		t.Errorf("Capture number not correct, was %v", q.Captured("number"))
+	}
+	if q.Captured("boolean") != left {
+		t.Errorf("Capture boolean not correct, was %v", q.Captured("boolean"))
+	}
+	if q.Captured("unary") != nil {
+		t.Errorf("Capture unary should not be set, was %v", q.Captured("unary"))
+	}
+}
+
+func TestQuery_Capture_failed_run(t *testing.T) {
+	q := NewQuery().Capture("any").MustBeCall()
+
+	err := q.Run(&ast.Identifier{Name: "a"})
+	if err == nil {
+		t.Fatalf("Test should have failed")
+	}
+
+	if q.Captured("any") != nil {
+		t.Errorf("Failed run should not capture, was %v", q.Captured("any"))
+	}
+}
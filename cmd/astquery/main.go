@@ -0,0 +1,266 @@
+// Command astquery searches JavaScript files with a selector, or with the rules of a rule file.
+//
+// Usage:
+//
+//	astquery [flags] selector [path...]
+//	astquery [flags] -rules file [path...]
+//
+// The paths are files, directories searched recursively, or glob patterns, the current directory
+// if none are given. Matches are printed one per line as file:line:column followed by the line of
+// source the match starts on, preceded by the severity, rule and message when running rules.
+// The matches can also be printed as JSON or as a SARIF 2.1.0 log.
+//
+// The exit status is 1 if there are matches, 2 if there are none but a file could not be searched,
+// and 0 otherwise, so the command can be used to fail builds.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/wolfgarnet/astquery"
+)
+
+const (
+	exitNoMatch = 0
+	exitMatch   = 1
+	exitError   = 2
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// options are the parsed command line
+type options struct {
+	rules            *astquery.RuleSet
+	selector         bool
+	paths            []string
+	count            bool
+	filesWithMatches bool
+	json             bool
+	sarif            bool
+	workers          int
+	extensions       []string
+}
+
+// parseOptions parses the command line, printing the usage on errors.
+func parseOptions(args []string, stderr io.Writer) (*options, error) {
+	flags := flag.NewFlagSet("astquery", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "Usage:\n  astquery [flags] selector [path...]\n  astquery [flags] -rules file [path...]\n\nFlags:\n")
+		flags.PrintDefaults()
+	}
+
+	o := &options{}
+	rulesFile := flags.String("rules", "", "run the rules of the JSON rule `file` instead of a selector")
+	flags.BoolVar(&o.count, "count", false, "print the number of matches per file")
+	flags.BoolVar(&o.filesWithMatches, "files-with-matches", false, "print the names of the files with matches")
+	flags.BoolVar(&o.json, "json", false, "print the matches as JSON")
+	flags.BoolVar(&o.sarif, "sarif", false, "print the matches as a SARIF 2.1.0 log")
+	flags.IntVar(&o.workers, "workers", 0, "number of files searched at once, the number of CPUs if 0")
+	extensions := flags.String("ext", ".js", "comma separated extensions of the files searched in directories")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	modes := 0
+	for _, mode := range []bool{o.count, o.filesWithMatches, o.json, o.sarif} {
+		if mode {
+			modes++
+		}
+	}
+	if modes > 1 {
+		return nil, fmt.Errorf("Only one of -count, -files-with-matches, -json and -sarif can be given")
+	}
+
+	o.paths = flags.Args()
+	o.extensions = strings.Split(*extensions, ",")
+	if *rulesFile != "" {
+		f, err := os.Open(*rulesFile)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		if o.rules, err = astquery.ReadRuleSet(f); err != nil {
+			return nil, fmt.Errorf("%v: %v", *rulesFile, err)
+		}
+	} else {
+		if len(o.paths) == 0 {
+			flags.Usage()
+			return nil, fmt.Errorf("No selector given")
+		}
+
+		query, err := astquery.Compile(o.paths[0])
+		if err != nil {
+			return nil, err
+		}
+
+		o.selector = true
+		o.rules = astquery.NewRuleSet("", "")
+		err = o.rules.Add(&astquery.Rule{
+			ID:       o.paths[0],
+			Severity: astquery.SeverityWarning,
+			Query:    query,
+		})
+		if err != nil {
+			return nil, err
+		}
+		o.paths = o.paths[1:]
+	}
+
+	if len(o.paths) == 0 {
+		o.paths = []string{"."}
+	}
+
+	return o, nil
+}
+
+// run runs the command, returning the exit status.
+func run(args []string, stdout, stderr io.Writer) int {
+	o, err := parseOptions(args, stderr)
+	if err == flag.ErrHelp {
+		return exitNoMatch
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "astquery: %v\n", err)
+		return exitError
+	}
+
+	scanner := o.rules.Scanner()
+	scanner.Workers = o.workers
+	scanner.Extensions = o.extensions
+
+	var findings []*astquery.Finding
+	failed := false
+	for result := range scanner.Scan(context.Background(), o.paths...) {
+		if result.Err != nil {
+			fmt.Fprintf(stderr, "astquery: %v\n", result.Err)
+			failed = true
+			continue
+		}
+
+		findings = append(findings, o.rules.Rule(result.Query).Finding(result.Match))
+	}
+
+	// The files are searched in parallel, sort the findings to print them in a stable order
+	order := map[*astquery.Rule]int{}
+	for i, r := range o.rules.Rules() {
+		order[r] = i
+	}
+	sort.Slice(findings, func(i, j int) bool {
+		a, b := findings[i].Match, findings[j].Match
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		if a.Idx0 != b.Idx0 {
+			return a.Idx0 < b.Idx0
+		}
+		return order[findings[i].Rule] < order[findings[j].Rule]
+	})
+
+	switch {
+	case o.count:
+		printCount(stdout, findings)
+	case o.filesWithMatches:
+		printFiles(stdout, findings)
+	case o.json:
+		if err := printJSON(stdout, findings, o.selector); err != nil {
+			fmt.Fprintf(stderr, "astquery: %v\n", err)
+			return exitError
+		}
+	case o.sarif:
+		if err := astquery.WriteSARIF(stdout, o.rules, findings); err != nil {
+			fmt.Fprintf(stderr, "astquery: %v\n", err)
+			return exitError
+		}
+	default:
+		printMatches(stdout, findings, o.selector)
+	}
+
+	if len(findings) > 0 {
+		return exitMatch
+	}
+	if failed {
+		return exitError
+	}
+
+	return exitNoMatch
+}
+
+// printMatches prints a line per finding.
+func printMatches(w io.Writer, findings []*astquery.Finding, selector bool) {
+	for _, f := range findings {
+		m := f.Match
+		fmt.Fprintf(w, "%v:%v:%v: ", m.Filename, m.Line, m.Column)
+		if !selector {
+			fmt.Fprintf(w, "[%v %v] ", f.Rule.Severity, f.Rule.ID)
+			if f.Message != "" {
+				fmt.Fprintf(w, "%v: ", f.Message)
+			}
+		}
+		fmt.Fprintln(w, m.Excerpt())
+	}
+}
+
+// printCount prints the number of findings of every file with findings.
+func printCount(w io.Writer, findings []*astquery.Finding) {
+	for i := 0; i < len(findings); {
+		j := i
+		for j < len(findings) && findings[j].Match.Filename == findings[i].Match.Filename {
+			j++
+		}
+		fmt.Fprintf(w, "%v:%v\n", findings[i].Match.Filename, j-i)
+		i = j
+	}
+}
+
+// printFiles prints the name of every file with findings.
+func printFiles(w io.Writer, findings []*astquery.Finding) {
+	for i, f := range findings {
+		if i == 0 || findings[i-1].Match.Filename != f.Match.Filename {
+			fmt.Fprintln(w, f.Match.Filename)
+		}
+	}
+}
+
+// jsonFinding is a finding in the output of -json
+type jsonFinding struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	Rule     string `json:"rule,omitempty"`
+	Severity string `json:"severity,omitempty"`
+	Message  string `json:"message,omitempty"`
+	Excerpt  string `json:"excerpt"`
+}
+
+// printJSON prints the findings as a JSON array.
+func printJSON(w io.Writer, findings []*astquery.Finding, selector bool) error {
+	out := make([]jsonFinding, len(findings))
+	for i, f := range findings {
+		out[i] = jsonFinding{
+			File:    f.Match.Filename,
+			Line:    f.Match.Line,
+			Column:  f.Match.Column,
+			Excerpt: f.Match.Excerpt(),
+		}
+		if !selector {
+			out[i].Rule = f.Rule.ID
+			out[i].Severity = string(f.Rule.Severity)
+			out[i].Message = f.Message
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(out)
+}
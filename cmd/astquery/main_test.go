@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		args   []string
+		status int
+		output string
+	}{
+		// Test 0
+		{[]string{"CallExpression", "../../testdata/scan/a.js", "../../testdata/scan/lib"}, exitMatch,
+			"../../testdata/scan/a.js:1:1: console.log(\"a\");\n" +
+				"../../testdata/scan/a.js:2:1: f();\n" +
+				"../../testdata/scan/lib/b.js:3:1: console.warn(x);\n"},
+
+		// Test 1
+		{[]string{"-rules", "testdata/rules.json", "../../testdata/scan/lib"}, exitMatch,
+			"../../testdata/scan/lib/b.js:1:5: [info no-var] Prefer declaring variables where they are used: var x = 1;\n" +
+				"../../testdata/scan/lib/b.js:3:1: [warning no-console] avoid calling console: console.warn(x);\n"},
+
+		// Test 2
+		{[]string{"-count", "CallExpression", "../../testdata/scan/a.js", "../../testdata/scan/lib"}, exitMatch,
+			"../../testdata/scan/a.js:2\n" +
+				"../../testdata/scan/lib/b.js:1\n"},
+
+		// Test 3
+		{[]string{"-files-with-matches", "CallExpression", "../../testdata/scan/a.js", "../../testdata/scan/lib"}, exitMatch,
+			"../../testdata/scan/a.js\n" +
+				"../../testdata/scan/lib/b.js\n"},
+
+		// Test 4
+		{[]string{"-json", "VariableExpression", "../../testdata/scan/lib"}, exitMatch,
+			`[
+  {
+    "file": "../../testdata/scan/lib/b.js",
+    "line": 1,
+    "column": 5,
+    "excerpt": "var x = 1;"
+  }
+]
+`},
+
+		// Test 5
+		{[]string{"-sarif", "-rules", "testdata/rules.json", "../../testdata/scan/missing.js"}, exitError,
+			`{
+  "$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+  "version": "2.1.0",
+  "runs": [
+    {
+      "tool": {
+        "driver": {
+          "name": "example",
+          "version": "1.0.0",
+          "rules": [
+            {
+              "id": "no-console",
+              "shortDescription": {
+                "text": "Calls to console should not be committed"
+              },
+              "defaultConfiguration": {
+                "level": "warning"
+              },
+              "properties": {
+                "tags": [
+                  "debug"
+                ]
+              }
+            },
+            {
+              "id": "no-var",
+              "shortDescription": {
+                "text": "Prefer declaring variables where they are used"
+              },
+              "defaultConfiguration": {
+                "level": "note"
+              }
+            }
+          ]
+        }
+      },
+      "results": []
+    }
+  ]
+}
+`},
+
+		// Test 6, no matches
+		{[]string{"ThisExpression", "../../testdata/scan/a.js"}, exitNoMatch, ""},
+
+		// Test 7, syntax errors in files are reported
+		{[]string{"ThisExpression", "../../testdata/scan"}, exitError, ""},
+
+		// Test 8, invalid selector
+		{[]string{"Call[", "../../testdata/scan"}, exitError, ""},
+
+		// Test 9, missing selector
+		{[]string{}, exitError, ""},
+
+		// Test 10, conflicting output modes
+		{[]string{"-json", "-count", "CallExpression"}, exitError, ""},
+
+		// Test 11, child selectors report the selected node
+		{[]string{"CallExpression > Identifier[name=x]", "../../testdata/scan/lib"}, exitMatch,
+			"../../testdata/scan/lib/b.js:3:14: console.warn(x);\n"},
+	}
+
+	for i, test := range tests {
+		var stdout, stderr bytes.Buffer
+		status := run(test.args, &stdout, &stderr)
+		if status != test.status {
+			t.Errorf("Test %v should exit with %v, was %v: %v", i, test.status, status, stderr.String())
+		}
+		if stdout.String() != test.output {
+			t.Errorf("Test %v output not correct, was\n%v", i, stdout.String())
+		}
+		if test.status == exitError && !strings.Contains(stderr.String(), "astquery: ") {
+			t.Errorf("Test %v should report an error, was %q", i, stderr.String())
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package astquery
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/robertkrimen/otto/ast"
+)
+
+func TestQuery_Evaluate(t *testing.T) {
+	program := parseProgram(t, `a = f(1);`)
+	assign := program.Body[0].(*ast.ExpressionStatement).Expression
+
+	q := NewQuery().MustBeAssign().Capture("assign").RightSide(NewQuery().MustBeCall().Capture("call")).AssignTarget()
+	result, err := q.Evaluate(assign)
+	if err != nil {
+		t.Fatalf("Test failed, %v", err)
+	}
+
+	right := assign.(*ast.AssignExpression).Right
+	if result.Node != assign.(*ast.AssignExpression).Left || result.Collected != assign {
+		t.Errorf("Result not correct, was %+v", result)
+	}
+	if result.Captures["assign"] != assign || result.Captures["call"] != right {
+		t.Errorf("Captures not correct, was %v", result.Captures)
+	}
+
+	// Evaluate does not modify the query
+	if q.Collected != nil || q.Captured("call") != nil {
+		t.Errorf("Query was modified")
+	}
+}
+
+func TestQuery_concurrent(t *testing.T) {
+	q := NewQuery().MustBeCall().Capture("call").
+		CalleePath("console.*").
+		Inside(NewQuery().MustBeFunctionLiteral()).
+		Args(NewQuery().Either(NewQuery().MustBeStringLiteral().Capture("message"), NewQuery().MustBeIdentifier().Capture("message")))
+
+	programs := make([]*ast.Program, 16)
+	for i := range programs {
+		src := ""
+		for j := 0; j <= i; j++ {
+			src += fmt.Sprintf("function f%v() { console.log('%v'); console.warn(x); g(1); }\n", j, j)
+		}
+		programs[i] = parseProgram(t, src)
+	}
+
+	var wg sync.WaitGroup
+	for round := 0; round < 4; round++ {
+		for i, program := range programs {
+			wg.Add(1)
+			go func(i int, program *ast.Program) {
+				defer wg.Done()
+
+				matches := q.FindAll(program)
+				if len(matches) != 2*(i+1) {
+					t.Errorf("Program %v should have %v matches, got %v", i, 2*(i+1), len(matches))
+					return
+				}
+				for _, m := range matches {
+					call := m.Node.(*ast.CallExpression)
+					if m.Capture("call") != call || m.Capture("message") != call.ArgumentList[0] {
+						t.Errorf("Captures of program %v not correct, was %v", i, m.Captures)
+					}
+				}
+			}(i, program)
+		}
+	}
+	wg.Wait()
+}
@@ -4,20 +4,14 @@ import "github.com/robertkrimen/otto/ast"
 
 // Empty
 type emptyQuery struct {
-	expression ast.Expression
 }
 
-func (qo *emptyQuery) run(e ast.Expression) error {
-	qo.expression = e
-	return nil
-}
-
-func (qo *emptyQuery) get() ast.Expression {
-	return qo.expression
+func (qo *emptyQuery) run(s *state, e ast.Node) (ast.Node, Captures, error) {
+	return e, nil, nil
 }
 
 // Empty does nothing, but collects the expression(if needed)
 func (q *Query) Empty() *Query {
-	q.operations = append(q.operations, &emptyQuery{})
+	q.add("Empty", &emptyQuery{})
 	return q
 }
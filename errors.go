@@ -0,0 +1,80 @@
+package astquery
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/robertkrimen/otto/ast"
+	"github.com/robertkrimen/otto/file"
+)
+
+// ErrMismatch is matched by every MismatchError using errors.Is
+var ErrMismatch = errors.New("query did not match")
+
+// MismatchError explains why a query did not match a node
+type MismatchError struct {
+	// Operation is the name of the query method adding the failing operation, e.g. "MustBeBinary"
+	Operation string
+
+	// Expected is the expected node kind, e.g. "BinaryExpression", if the operation expects one
+	Expected string
+
+	// Actual is the kind of the node, e.g. "CallExpression"
+	Actual string
+
+	// Node is the node the operation failed on, and Idx its position
+	Node ast.Node
+	Idx  file.Idx
+
+	Message string
+
+	// Causes holds the errors of sub queries leading to the mismatch, e.g. of each query given to Either
+	Causes []error
+}
+
+// mismatch returns an error for a node rejected by an operation. The operation name is set by the query running it.
+func mismatch(node ast.Node, expected string, format string, args ...interface{}) *MismatchError {
+	idx, _ := nodeRange(node)
+	return &MismatchError{
+		Expected: expected,
+		Actual:   kindOf(node),
+		Node:     node,
+		Idx:      idx,
+		Message:  fmt.Sprintf(format, args...),
+	}
+}
+
+// because adds the causes of the mismatch, ignoring nil errors.
+func (e *MismatchError) because(causes ...error) *MismatchError {
+	for _, cause := range causes {
+		if cause != nil {
+			e.Causes = append(e.Causes, cause)
+		}
+	}
+
+	return e
+}
+
+func (e *MismatchError) Error() string {
+	if len(e.Causes) == 0 {
+		return e.Message
+	}
+
+	causes := make([]string, len(e.Causes))
+	for i, cause := range e.Causes {
+		causes[i] = cause.Error()
+	}
+
+	return fmt.Sprintf("%v: [%v]", e.Message, strings.Join(causes, "; "))
+}
+
+// Unwrap returns the causes, so errors.Is and errors.As also inspect them.
+func (e *MismatchError) Unwrap() []error {
+	return e.Causes
+}
+
+// Is reports whether the target is ErrMismatch.
+func (e *MismatchError) Is(target error) bool {
+	return target == ErrMismatch
+}
@@ -0,0 +1,73 @@
+package astquery
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/robertkrimen/otto/ast"
+)
+
+func TestMismatchError(t *testing.T) {
+	program := parseProgram(t, `a = f(1);`)
+	assign := program.Body[0].(*ast.ExpressionStatement).Expression
+
+	err := NewQuery().MustBeAssign().RightSide(NewQuery().MustBeBinary()).Run(assign)
+	if !errors.Is(err, ErrMismatch) {
+		t.Fatalf("Expected a mismatch, was %v", err)
+	}
+
+	var mismatch *MismatchError
+	if !errors.As(err, &mismatch) {
+		t.Fatalf("Expected a MismatchError, was %T", err)
+	}
+	if mismatch.Operation != "RightSide" || mismatch.Node != assign || mismatch.Actual != "AssignExpression" {
+		t.Errorf("Mismatch not correct, was %+v", mismatch)
+	}
+	if len(mismatch.Causes) != 1 {
+		t.Fatalf("Expected 1 cause, got %v", len(mismatch.Causes))
+	}
+
+	cause, isMismatch := mismatch.Causes[0].(*MismatchError)
+	if !isMismatch {
+		t.Fatalf("Expected the cause to be a MismatchError, was %T", mismatch.Causes[0])
+	}
+	if cause.Operation != "MustBeBinary" || cause.Expected != "BinaryExpression" || cause.Actual != "CallExpression" {
+		t.Errorf("Cause not correct, was %+v", cause)
+	}
+	if cause.Idx != assign.(*ast.AssignExpression).Right.Idx0() {
+		t.Errorf("Cause position not correct, was %v", cause.Idx)
+	}
+}
+
+func TestMismatchError_either(t *testing.T) {
+	program := parseProgram(t, `f(1);`)
+	call := program.Body[0].(*ast.ExpressionStatement).Expression
+
+	err := NewQuery().Either(NewQuery().MustBeBinary(), NewQuery().MustBeUnary()).Run(call)
+
+	var mismatch *MismatchError
+	if !errors.As(err, &mismatch) {
+		t.Fatalf("Expected a MismatchError, was %T", err)
+	}
+	if mismatch.Operation != "Either" || len(mismatch.Causes) != 2 {
+		t.Fatalf("Mismatch not correct, was %+v", mismatch)
+	}
+
+	var expected []string
+	for _, cause := range mismatch.Causes {
+		expected = append(expected, cause.(*MismatchError).Expected)
+	}
+	if expected[0] != "BinaryExpression" || expected[1] != "UnaryExpression" {
+		t.Errorf("Causes not correct, was %v", expected)
+	}
+}
+
+func TestMismatchError_match(t *testing.T) {
+	program := parseProgram(t, `f(1);`)
+	call := program.Body[0].(*ast.ExpressionStatement).Expression
+
+	err := NewQuery().MustBeCall().Run(call)
+	if err != nil {
+		t.Fatalf("Test failed, %v", err)
+	}
+}
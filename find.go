@@ -0,0 +1,58 @@
+package astquery
+
+import (
+	"context"
+
+	"github.com/robertkrimen/otto/ast"
+)
+
+// FindOptions controls a search with FindAllContext
+type FindOptions struct {
+	// Limit stops the search after this many matches, zero means no limit
+	Limit int
+}
+
+// FindAll runs the query at every node below and including root, statements included,
+// and returns all matches in source order.
+func (ql *Query) FindAll(root ast.Node) []*Match {
+	matches, _ := ql.FindAllContext(context.Background(), root, FindOptions{})
+	return matches
+}
+
+// FindAllContext is like FindAll, but stops after the limit given by the options,
+// or when the context is done. In the latter case the matches found so far are
+// returned together with the error of the context.
+func (ql *Query) FindAllContext(ctx context.Context, root ast.Node, options FindOptions) ([]*Match, error) {
+	var matches []*Match
+	var err error
+	s := &state{parents: newParents(root)}
+
+	visited := 0
+	walk(root, func(node ast.Node, path []ast.Node) bool {
+		if err != nil || (options.Limit > 0 && len(matches) >= options.Limit) {
+			return false
+		}
+
+		// Checking the context for every node is needlessly expensive
+		if visited%256 == 0 {
+			if err = ctx.Err(); err != nil {
+				return false
+			}
+		}
+		visited++
+
+		if result, runErr := ql.eval(s, node); runErr == nil {
+			matches = append(matches, newMatch(node, result, path))
+		}
+
+		return true
+	})
+
+	if program, isProgram := root.(*ast.Program); isProgram {
+		for _, m := range matches {
+			m.locate(program.File)
+		}
+	}
+
+	return matches, err
+}
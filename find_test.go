@@ -0,0 +1,72 @@
+package astquery
+
+import (
+	"context"
+	"testing"
+
+	"github.com/robertkrimen/otto/ast"
+)
+
+func TestFindAll(t *testing.T) {
+	program := parseProgram(t, "f(g(1), function() {\n  h();\n});\nif (a) { i(); }")
+
+	matches := NewQuery().MustBeCall().FindAll(program)
+
+	names := []string{"f", "g", "h", "i"}
+	if len(matches) != len(names) {
+		t.Fatalf("Expected %v matches, got %v", len(names), len(matches))
+	}
+
+	for i, name := range names {
+		call := matches[i].Node.(*ast.CallExpression)
+		if callee := call.Callee.(*ast.Identifier); callee.Name != name {
+			t.Errorf("Match %v should be a call to %v, was %v", i, name, callee.Name)
+		}
+	}
+
+	if matches[2].Line != 2 || matches[2].Column != 3 {
+		t.Errorf("Match 2 should be at 2:3, was %v:%v", matches[2].Line, matches[2].Column)
+	}
+}
+
+func TestFindAll_statements(t *testing.T) {
+	program := parseProgram(t, `if (a) { if (b) {} } while (c) {}`)
+
+	matches := NewQuery().MustBeIf().FindAll(program)
+	if len(matches) != 2 {
+		t.Errorf("Expected 2 matches, got %v", len(matches))
+	}
+
+	matches = MustCompile("IfStatement > BlockStatement > IfStatement").FindAll(program)
+	if len(matches) != 1 {
+		t.Errorf("Expected 1 match, got %v", len(matches))
+	}
+}
+
+func TestFindAllContext_limit(t *testing.T) {
+	program := parseProgram(t, `f(); g(); h();`)
+
+	matches, err := NewQuery().MustBeCall().FindAllContext(context.Background(), program, FindOptions{Limit: 2})
+	if err != nil {
+		t.Fatalf("Test failed, %v", err)
+	}
+
+	if len(matches) != 2 {
+		t.Errorf("Expected 2 matches, got %v", len(matches))
+	}
+}
+
+func TestFindAllContext_canceled(t *testing.T) {
+	program := parseProgram(t, `f(); g(); h();`)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	matches, err := NewQuery().MustBeCall().FindAllContext(ctx, program, FindOptions{})
+	if err != context.Canceled {
+		t.Errorf("Expected the search to be canceled, was %v", err)
+	}
+	if len(matches) != 0 {
+		t.Errorf("Expected no matches, got %v", len(matches))
+	}
+}
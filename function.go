@@ -1,29 +1,21 @@
 package astquery
 
 import (
-	"fmt"
 	"github.com/robertkrimen/otto/ast"
 )
 
 type functionLiteralQuery struct {
-	function ast.Expression
 }
 
-func (qo *functionLiteralQuery) run(e ast.Expression) error {
-	var isFLiteral bool
-	qo.function, isFLiteral = e.(*ast.FunctionLiteral)
-	if !isFLiteral {
-		return fmt.Errorf("Expression is not a function literal")
+func (qo *functionLiteralQuery) run(s *state, e ast.Node) (ast.Node, Captures, error) {
+	if _, isFLiteral := e.(*ast.FunctionLiteral); !isFLiteral {
+		return nil, nil, mismatch(e, "FunctionLiteral", "Expression is not a function literal, was %T", e)
 	}
 
-	return nil
-}
-
-func (qo *functionLiteralQuery) get() ast.Expression {
-	return qo.function
+	return e, nil, nil
 }
 
 func (q *Query) MustBeFunctionLiteral() *Query {
-	q.operations = append(q.operations, &functionLiteralQuery{})
+	q.add("MustBeFunctionLiteral", &functionLiteralQuery{})
 	return q
 }
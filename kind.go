@@ -0,0 +1,167 @@
+package astquery
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/robertkrimen/otto/ast"
+)
+
+// nodeKinds holds the names of every node type in the otto ast package
+var nodeKinds = map[string]bool{
+	"ArrayLiteral":          true,
+	"AssignExpression":      true,
+	"BadExpression":         true,
+	"BinaryExpression":      true,
+	"BooleanLiteral":        true,
+	"BracketExpression":     true,
+	"CallExpression":        true,
+	"ConditionalExpression": true,
+	"DotExpression":         true,
+	"EmptyExpression":       true,
+	"FunctionLiteral":       true,
+	"Identifier":            true,
+	"NewExpression":         true,
+	"NullLiteral":           true,
+	"NumberLiteral":         true,
+	"ObjectLiteral":         true,
+	"RegExpLiteral":         true,
+	"SequenceExpression":    true,
+	"StringLiteral":         true,
+	"ThisExpression":        true,
+	"UnaryExpression":       true,
+	"VariableExpression":    true,
+
+	"BadStatement":        true,
+	"BlockStatement":      true,
+	"BranchStatement":     true,
+	"CaseStatement":       true,
+	"CatchStatement":      true,
+	"DebuggerStatement":   true,
+	"DoWhileStatement":    true,
+	"EmptyStatement":      true,
+	"ExpressionStatement": true,
+	"ForInStatement":      true,
+	"ForStatement":        true,
+	"FunctionStatement":   true,
+	"IfStatement":         true,
+	"LabelledStatement":   true,
+	"ReturnStatement":     true,
+	"SwitchStatement":     true,
+	"ThrowStatement":      true,
+	"TryStatement":        true,
+	"VariableStatement":   true,
+	"WhileStatement":      true,
+	"WithStatement":       true,
+
+	"Program": true,
+}
+
+// kindOf returns the name of the node type, e.g. "BinaryExpression".
+func kindOf(node ast.Node) string {
+	if node == nil {
+		return ""
+	}
+
+	t := reflect.TypeOf(node)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t.Name()
+}
+
+// kindQuery requires the current expression to be of a given kind
+type kindQuery struct {
+	kind string
+}
+
+func (qo *kindQuery) run(s *state, e ast.Node) (ast.Node, Captures, error) {
+	if kindOf(e) != qo.kind {
+		return nil, nil, mismatch(e, qo.kind, "Expression is not %v, was %T", qo.kind, e)
+	}
+
+	return e, nil, nil
+}
+
+// Is restricts the expression to be of the given kind, which is the name of the node type
+// in the otto ast package, e.g. "ConditionalExpression". Is panics if there is no such node type.
+func (q *Query) Is(kind string) *Query {
+	if !nodeKinds[kind] {
+		panic(fmt.Sprintf("Unknown node kind %q", kind))
+	}
+
+	return q.is("Is", kind)
+}
+
+func (q *Query) is(name, kind string) *Query {
+	q.add(name, &kindQuery{
+		kind: kind,
+	})
+	return q
+}
+
+// MustBeArrayLiteral restricts the expression to be an array literal
+func (q *Query) MustBeArrayLiteral() *Query {
+	return q.is("MustBeArrayLiteral", "ArrayLiteral")
+}
+
+// MustBeBooleanLiteral restricts the expression to be a boolean literal
+func (q *Query) MustBeBooleanLiteral() *Query {
+	return q.is("MustBeBooleanLiteral", "BooleanLiteral")
+}
+
+// MustBeBracket restricts the expression to be a bracket expression, e.g. a[b]
+func (q *Query) MustBeBracket() *Query {
+	return q.is("MustBeBracket", "BracketExpression")
+}
+
+// MustBeConditional restricts the expression to be a conditional expression, e.g. a ? b : c
+func (q *Query) MustBeConditional() *Query {
+	return q.is("MustBeConditional", "ConditionalExpression")
+}
+
+// MustBeDot restricts the expression to be a dot expression, e.g. a.b
+func (q *Query) MustBeDot() *Query {
+	return q.is("MustBeDot", "DotExpression")
+}
+
+// MustBeIdentifier restricts the expression to be an identifier
+func (q *Query) MustBeIdentifier() *Query {
+	return q.is("MustBeIdentifier", "Identifier")
+}
+
+// MustBeNullLiteral restricts the expression to be null
+func (q *Query) MustBeNullLiteral() *Query {
+	return q.is("MustBeNullLiteral", "NullLiteral")
+}
+
+// MustBeNumberLiteral restricts the expression to be a number literal
+func (q *Query) MustBeNumberLiteral() *Query {
+	return q.is("MustBeNumberLiteral", "NumberLiteral")
+}
+
+// MustBeRegExpLiteral restricts the expression to be a regular expression literal
+func (q *Query) MustBeRegExpLiteral() *Query {
+	return q.is("MustBeRegExpLiteral", "RegExpLiteral")
+}
+
+// MustBeSequence restricts the expression to be a sequence expression, e.g. a, b
+func (q *Query) MustBeSequence() *Query {
+	return q.is("MustBeSequence", "SequenceExpression")
+}
+
+// MustBeStringLiteral restricts the expression to be a string literal
+func (q *Query) MustBeStringLiteral() *Query {
+	return q.is("MustBeStringLiteral", "StringLiteral")
+}
+
+// MustBeThis restricts the expression to be this
+func (q *Query) MustBeThis() *Query {
+	return q.is("MustBeThis", "ThisExpression")
+}
+
+// MustBeVariable restricts the expression to be a variable expression, e.g. a = 1 in var a = 1
+func (q *Query) MustBeVariable() *Query {
+	return q.is("MustBeVariable", "VariableExpression")
+}
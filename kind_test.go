@@ -0,0 +1,70 @@
+package astquery
+
+import (
+	"testing"
+)
+
+func TestKinds(t *testing.T) {
+	tests := []struct {
+		query *Query
+		kind  string
+	}{
+		{NewQuery().MustBeArrayLiteral(), "ArrayLiteral"},
+		{NewQuery().MustBeBooleanLiteral(), "BooleanLiteral"},
+		{NewQuery().MustBeBracket(), "BracketExpression"},
+		{NewQuery().MustBeConditional(), "ConditionalExpression"},
+		{NewQuery().MustBeDot(), "DotExpression"},
+		{NewQuery().MustBeIdentifier(), "Identifier"},
+		{NewQuery().MustBeNew(), "NewExpression"},
+		{NewQuery().MustBeNullLiteral(), "NullLiteral"},
+		{NewQuery().MustBeNumberLiteral(), "NumberLiteral"},
+		{NewQuery().MustBeRegExpLiteral(), "RegExpLiteral"},
+		{NewQuery().MustBeSequence(), "SequenceExpression"},
+		{NewQuery().MustBeStringLiteral(), "StringLiteral"},
+		{NewQuery().MustBeThis(), "ThisExpression"},
+		{NewQuery().MustBeVariable(), "VariableExpression"},
+		{NewQuery().Is("ObjectLiteral"), "ObjectLiteral"},
+	}
+
+	program := parseProgram(t, `
+		[1];
+		true;
+		a[b];
+		a ? b : c;
+		a.b;
+		a;
+		new A();
+		null;
+		1;
+		/a/;
+		a, b;
+		"a";
+		this;
+		var a = 1;
+		({});
+	`)
+
+	for i, test := range tests {
+		matches := test.query.FindAll(program)
+		for _, m := range matches {
+			if kind := kindOf(m.Node); kind != test.kind {
+				t.Errorf("Test %v should only match %v, matched %v", i, test.kind, kind)
+			}
+		}
+
+		matches = test.query.RunProgram(program)
+		if len(matches) != 1 {
+			t.Errorf("Test %v should match one statement, matched %v", i, len(matches))
+		}
+	}
+}
+
+func TestIs_unknown_kind(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Is should panic on an unknown node kind")
+		}
+	}()
+
+	NewQuery().Is("Call")
+}
@@ -0,0 +1,197 @@
+package astquery
+
+import (
+	"strings"
+
+	"github.com/robertkrimen/otto/ast"
+	"github.com/robertkrimen/otto/file"
+)
+
+// Match is a single hit of a query
+type Match struct {
+	// Node is the node the query matched
+	Node ast.Node
+
+	// Result is the expression the query ended at, which differs from Node when the query navigates the tree
+	Result ast.Node
+
+	// Collected is the expression collected by the query, if any
+	Collected ast.Node
+
+	// Captures holds the expressions captured by name
+	Captures Captures
+
+	// Path holds the ancestors of Node, starting from the root
+	Path []ast.Node
+
+	// Idx0 and Idx1 is the source index range of Node
+	Idx0, Idx1 file.Idx
+
+	// Filename, Line and Column is the position of Node, if the source is known
+	Filename     string
+	Line, Column int
+
+	// EndLine and EndColumn is the position following Node, if the source is known
+	EndLine, EndColumn int
+
+	// file is the file the match was located in, if known
+	file *file.File
+}
+
+// newMatch returns a match for a node given the result of the query and the ancestors of the node.
+func newMatch(node ast.Node, result *Result, path []ast.Node) *Match {
+	m := &Match{
+		Node:      node,
+		Result:    result.Node,
+		Collected: result.Collected,
+		Captures:  result.Captures,
+		Path:      append([]ast.Node(nil), path...),
+	}
+	m.Idx0, m.Idx1 = nodeRange(node)
+
+	return m
+}
+
+// Capture returns the expression captured by name, or nil.
+func (m *Match) Capture(name string) ast.Node {
+	return m.Captures[name]
+}
+
+// locate sets the position of the match from the file the node was parsed from.
+func (m *Match) locate(f *file.File) {
+	if f == nil {
+		return
+	}
+
+	m.file = f
+	m.Filename = f.Name()
+	if position := f.Position(m.Idx0); position != nil {
+		m.Line = position.Line
+		m.Column = position.Column
+	}
+
+	// The position following a node ending the source is not part of the file
+	if position := f.Position(m.Idx1); position != nil {
+		m.EndLine = position.Line
+		m.EndColumn = position.Column
+	} else if position := f.Position(m.Idx1 - 1); position != nil && m.Idx1 > m.Idx0 {
+		m.EndLine = position.Line
+		m.EndColumn = position.Column + 1
+	}
+}
+
+// Text returns the source of a node in the program the match was found in, e.g. a capture,
+// or an empty string if the source is not known.
+func (m *Match) Text(node ast.Node) string {
+	if m.file == nil || node == nil {
+		return ""
+	}
+
+	idx0, idx1 := nodeRange(node)
+	start, end := int(idx0)-m.file.Base(), int(idx1)-m.file.Base()
+	src := m.file.Source()
+	if start < 0 || end > len(src) || start >= end {
+		return ""
+	}
+
+	return src[start:end]
+}
+
+// Excerpt returns the line of source the match starts on, without surrounding whitespace,
+// or an empty string if the source is not known.
+func (m *Match) Excerpt() string {
+	if m.file == nil {
+		return ""
+	}
+
+	src := m.file.Source()
+	start := int(m.Idx0) - m.file.Base()
+	if start < 0 || start > len(src) {
+		return ""
+	}
+
+	begin := strings.LastIndexByte(src[:start], '\n') + 1
+	end := strings.IndexByte(src[start:], '\n')
+	if end < 0 {
+		end = len(src)
+	} else {
+		end += start
+	}
+
+	return strings.TrimSpace(src[begin:end])
+}
+
+// nodeRange returns the source index range of a node.
+func nodeRange(node ast.Node) (idx0, idx1 file.Idx) {
+	if node == nil {
+		return 0, 0
+	}
+
+	return nodeStart(node), nodeEnd(node)
+}
+
+// nodeStart returns the index of the first character of a node. otto computes the start of programs
+// and sequences from their first child and panics if they have none, e.g. the empty initializer of a
+// for statement, in which case the start is zero.
+func nodeStart(node ast.Node) file.Idx {
+	switch t := node.(type) {
+	case *ast.Program:
+		if len(t.Body) == 0 {
+			return 0
+		}
+	case *ast.SequenceExpression:
+		if len(t.Sequence) == 0 {
+			return 0
+		}
+	}
+
+	return node.Idx0()
+}
+
+// nodeEnd returns the index following a node. otto computes the end of programs, sequences, switch
+// and case statements from a child and panics if they have none, so these are computed here, with
+// the end of the discriminant, test or default keyword of empty switches and cases, and zero for
+// empty programs and sequences. Statements ending with their body are followed too, as the body may
+// be such a statement.
+func nodeEnd(node ast.Node) file.Idx {
+	switch t := node.(type) {
+	case *ast.Program:
+		if len(t.Body) == 0 {
+			return 0
+		}
+		return nodeEnd(t.Body[len(t.Body)-1])
+	case *ast.SequenceExpression:
+		if len(t.Sequence) == 0 {
+			return 0
+		}
+		return nodeEnd(t.Sequence[len(t.Sequence)-1])
+	case *ast.SwitchStatement:
+		if len(t.Body) == 0 {
+			return t.Discriminant.Idx1()
+		}
+		return nodeEnd(t.Body[len(t.Body)-1])
+	case *ast.CaseStatement:
+		if len(t.Consequent) > 0 {
+			return nodeEnd(t.Consequent[len(t.Consequent)-1])
+		}
+		if t.Test == nil {
+			return t.Case + file.Idx(len("default"))
+		}
+		return t.Test.Idx1()
+	case *ast.IfStatement:
+		if t.Alternate != nil {
+			return nodeEnd(t.Alternate)
+		}
+		return nodeEnd(t.Consequent)
+	case *ast.ForInStatement:
+		return nodeEnd(t.Body)
+	case *ast.ForStatement:
+		return nodeEnd(t.Body)
+	case *ast.WhileStatement:
+		return nodeEnd(t.Body)
+	case *ast.WithStatement:
+		return nodeEnd(t.Body)
+	}
+
+	return node.Idx1()
+}
@@ -0,0 +1,117 @@
+package astquery
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/robertkrimen/otto/ast"
+)
+
+func TestMatch_Path(t *testing.T) {
+	src := `function f() { if (a) { return g(); } }`
+	program := parseProgram(t, src)
+
+	matches := NewQuery().MustBeCall().RunProgram(program)
+	if len(matches) != 1 {
+		t.Fatalf("Expected 1 match, got %v", len(matches))
+	}
+
+	m := matches[0]
+	kinds := []string{"*ast.Program", "*ast.FunctionStatement", "*ast.FunctionLiteral", "*ast.BlockStatement", "*ast.IfStatement", "*ast.BlockStatement", "*ast.ReturnStatement"}
+	if len(m.Path) != len(kinds) {
+		t.Fatalf("Expected path of length %v, got %v", len(kinds), len(m.Path))
+	}
+	for i, kind := range kinds {
+		if actual := typeName(m.Path[i]); actual != kind {
+			t.Errorf("Path element %v should be %v, was %v", i, kind, actual)
+		}
+	}
+
+	if text := src[m.Idx0-1 : m.Idx1-1]; text != "g()" {
+		t.Errorf("Match range should cover g(), was %q", text)
+	}
+}
+
+func TestMatch_Collected(t *testing.T) {
+	program := parseProgram(t, `a = f(); b = g();`)
+
+	matches := NewQuery().MustBeCallD(true).Collect().RunProgram(program)
+	if len(matches) != 2 {
+		t.Fatalf("Expected 2 matches, got %v", len(matches))
+	}
+
+	for i, name := range []string{"f", "g"} {
+		if _, isAssign := matches[i].Node.(*ast.AssignExpression); !isAssign {
+			t.Errorf("Match %v should be an assign expression, was %T", i, matches[i].Node)
+		}
+		call := matches[i].Collected.(*ast.CallExpression)
+		if callee := call.Callee.(*ast.Identifier); callee.Name != name {
+			t.Errorf("Match %v should have collected a call to %v, was %v", i, name, callee.Name)
+		}
+	}
+}
+
+func typeName(node ast.Node) string {
+	return fmt.Sprintf("%T", node)
+}
+
+func TestMatch_Excerpt(t *testing.T) {
+	matches, err := QuerySource("a = 1;\n\n  if (b) { f(a); }\ng()", NewQuery().MustBeCall())
+	if err != nil {
+		t.Fatalf("Test failed, %v", err)
+	}
+
+	excerpts := []string{"if (b) { f(a); }", "g()"}
+	if len(matches) != len(excerpts) {
+		t.Fatalf("Expected %v matches, got %v", len(excerpts), len(matches))
+	}
+	for i, excerpt := range excerpts {
+		if matches[i].Excerpt() != excerpt {
+			t.Errorf("Excerpt %v not correct, was %q", i, matches[i].Excerpt())
+		}
+	}
+
+	if (&Match{}).Excerpt() != "" {
+		t.Errorf("Excerpt without source should be empty")
+	}
+}
+
+func TestMatch_empty_switch(t *testing.T) {
+	tests := []struct {
+		src      string
+		selector string
+		position [4]int
+	}{
+		// Test 0
+		{"switch (a) {\n  case 1:\n}", "CaseStatement", [4]int{2, 3, 2, 9}},
+
+		// Test 1
+		{"switch (a) {\n  default:\n}", "CaseStatement", [4]int{2, 3, 2, 10}},
+
+		// Test 2, otto does not record the start of switch statements
+		{"switch (a) { case 1: }", "SwitchStatement", [4]int{0, 0, 1, 20}},
+
+		// Test 3
+		{"switch (a) {}", "SwitchStatement", [4]int{0, 0, 1, 10}},
+
+		// Test 4
+		{"switch (a) { default: switch (b) {} }", "CaseStatement", [4]int{1, 14, 1, 32}},
+	}
+
+	for i, test := range tests {
+		matches, err := QuerySource(test.src, MustCompile(test.selector))
+		if err != nil {
+			t.Errorf("Test %v failed, %v", i, err)
+			continue
+		}
+		if len(matches) != 1 {
+			t.Errorf("Test %v should have 1 match, got %v", i, len(matches))
+			continue
+		}
+
+		m := matches[0]
+		if position := [4]int{m.Line, m.Column, m.EndLine, m.EndColumn}; position != test.position {
+			t.Errorf("Test %v should be at %v, was %v", i, test.position, position)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package astquery
+
+import (
+	"strings"
+
+	"github.com/robertkrimen/otto/ast"
+)
+
+// memberPath returns the segments of a member expression, e.g. ["console", "log"] for console.log.
+// Identifiers, this, dot expressions and bracket expressions with string members are resolved, any
+// other part of the chain, e.g. a call or a computed member, is an empty segment.
+func memberPath(e ast.Node) []string {
+	switch t := e.(type) {
+	case *ast.Identifier:
+		return []string{t.Name}
+	case *ast.ThisExpression:
+		return []string{"this"}
+	case *ast.DotExpression:
+		return append(memberPath(t.Left), t.Identifier.Name)
+	case *ast.BracketExpression:
+		member := ""
+		if s, isString := t.Member.(*ast.StringLiteral); isString {
+			member = s.Value
+		}
+		return append(memberPath(t.Left), member)
+	default:
+		return []string{""}
+	}
+}
+
+// matchMemberPath matches path segments against a dotted pattern, where * matches any one segment.
+func matchMemberPath(path []string, pattern string) bool {
+	segments := strings.Split(pattern, ".")
+	if len(segments) != len(path) {
+		return false
+	}
+
+	for i, segment := range segments {
+		if segment != "*" && segment != path[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// memberPathQuery filters member expressions on their dotted path
+type memberPathQuery struct {
+	patterns []string
+	callee   bool
+}
+
+func (qo *memberPathQuery) run(s *state, e ast.Node) (ast.Node, Captures, error) {
+	member := e
+	if qo.callee {
+		switch t := e.(type) {
+		case *ast.CallExpression:
+			member = t.Callee
+		case *ast.NewExpression:
+			member = t.Callee
+		default:
+			return nil, nil, mismatch(e, "CallExpression", "Expression is not a call, was %T", e)
+		}
+	}
+
+	// Only the inner parts of a chain may be unresolvable
+	switch member.(type) {
+	case *ast.Identifier, *ast.ThisExpression, *ast.DotExpression, *ast.BracketExpression:
+	default:
+		return nil, nil, mismatch(e, "", "Expression is not a member chain, was %T", member)
+	}
+
+	path := memberPath(member)
+	for _, pattern := range qo.patterns {
+		if matchMemberPath(path, pattern) {
+			return e, nil, nil
+		}
+	}
+
+	return nil, nil, mismatch(e, "", "Member path %v does not match %v", strings.Join(path, "."), strings.Join(qo.patterns, ", "))
+}
+
+// MemberPath will only pass if the expression is a member chain matching one of the dotted patterns,
+// e.g. "console.log". Dot expressions and bracket expressions with string members are followed.
+// A * in the pattern matches any one segment, including ones that cannot be resolved, e.g.
+// "*.then" matches both p.then and fetch(url).then. A chain is an identifier, this, or a dot or
+// bracket expression, so "*" does not match a call or a literal.
+func (q *Query) MemberPath(patterns ...string) *Query {
+	q.add("MemberPath", &memberPathQuery{
+		patterns: patterns,
+	})
+	return q
+}
+
+// CalleePath will only pass if the callee of the call or new expression matches one of the dotted patterns,
+// see MemberPath.
+func (q *Query) CalleePath(patterns ...string) *Query {
+	q.add("CalleePath", &memberPathQuery{
+		patterns: patterns,
+		callee:   true,
+	})
+	return q
+}
@@ -0,0 +1,58 @@
+package astquery
+
+import (
+	"testing"
+)
+
+func TestCalleePath(t *testing.T) {
+	tests := []struct {
+		src     string
+		query   *Query
+		matches int
+	}{
+		// Test 0
+		{`console.log(a); foo.log(a); log(a);`, NewQuery().CalleePath("console.log"), 1},
+
+		// Test 1
+		{`console.log(a); foo.log(a); log(a);`, NewQuery().CalleePath("*.log"), 2},
+
+		// Test 2
+		{`a.b.c(); a.b(); b.c();`, NewQuery().CalleePath("a.b.c"), 1},
+
+		// Test 3
+		{`window["eval"](a); window[name](a); window.eval(a);`, NewQuery().CalleePath("window.eval"), 2},
+
+		// Test 4
+		{`window[name](a);`, NewQuery().CalleePath("window.*"), 1},
+
+		// Test 5
+		{`fetch(a).then(f); p.then(f); p.catch(f);`, NewQuery().CalleePath("*.then"), 2},
+
+		// Test 6
+		{`this.f(); that.f();`, NewQuery().CalleePath("this.f"), 1},
+
+		// Test 7
+		{`new foo.Bar(); new Bar(); foo.Bar();`, NewQuery().CalleePath("foo.Bar", "Baz"), 2},
+
+		// Test 8
+		{`a = document.cookie; b = document.title;`, NewQuery().RightSide(NewQuery().MemberPath("document.cookie")), 1},
+
+		// Test 9, only member chains pass
+		{`var a; var b = 1; c = f(); d = e;`, NewQuery().RightSide(NewQuery().MemberPath("*")), 1},
+
+		// Test 10
+		{`(function() {})(); f()();`, NewQuery().CalleePath("*"), 1},
+	}
+
+	for i, test := range tests {
+		matches, err := QuerySource(test.src, test.query)
+		if err != nil {
+			t.Errorf("Test %v failed, %v", i, err)
+			continue
+		}
+
+		if len(matches) != test.matches {
+			t.Errorf("Test %v should have %v matches, got %v", i, test.matches, len(matches))
+		}
+	}
+}
@@ -1,29 +1,21 @@
 package astquery
 
 import (
-	"fmt"
 	"github.com/robertkrimen/otto/ast"
 )
 
 type mustBeObjectLiteral struct {
-	literal *ast.ObjectLiteral
 }
 
-func (qo *mustBeObjectLiteral) run(e ast.Expression) error {
-	object, isObject := e.(*ast.ObjectLiteral)
-	if !isObject {
-		return fmt.Errorf("Not an object literal")
+func (qo *mustBeObjectLiteral) run(s *state, e ast.Node) (ast.Node, Captures, error) {
+	if _, isObject := e.(*ast.ObjectLiteral); !isObject {
+		return nil, nil, mismatch(e, "ObjectLiteral", "Not an object literal, was %T", e)
 	}
 
-	qo.literal = object
-	return nil
-}
-
-func (qo *mustBeObjectLiteral) get() ast.Expression {
-	return qo.literal
+	return e, nil, nil
 }
 
 func (q *Query) MustBeObjectLiteral() *Query {
-	q.operations = append(q.operations, &mustBeObjectLiteral{})
+	q.add("MustBeObjectLiteral", &mustBeObjectLiteral{})
 	return q
 }
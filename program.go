@@ -0,0 +1,76 @@
+package astquery
+
+import (
+	"github.com/robertkrimen/otto/ast"
+)
+
+// RunProgram runs the query on every statement level expression in the program,
+// including those in nested blocks and function bodies and the functions of function
+// declarations, and returns all matches.
+func (ql *Query) RunProgram(program *ast.Program) []*Match {
+	var matches []*Match
+	s := &state{parents: newParents(program)}
+	walk(program, func(node ast.Node, path []ast.Node) bool {
+		statement, isStatement := node.(ast.Statement)
+		if !isStatement {
+			return true
+		}
+
+		path = append(path, statement)
+		for _, e := range statementExpressions(statement) {
+			if result, err := ql.eval(s, e); err == nil {
+				matches = append(matches, newMatch(e, result, path))
+			}
+		}
+
+		return true
+	})
+
+	return matches
+}
+
+// statementExpressions returns the expressions directly held by a statement.
+func statementExpressions(statement ast.Statement) []ast.Expression {
+	var expressions []ast.Expression
+	add := func(e ast.Expression) {
+		if e != nil {
+			expressions = append(expressions, e)
+		}
+	}
+
+	switch s := statement.(type) {
+	case *ast.CaseStatement:
+		add(s.Test)
+	case *ast.DoWhileStatement:
+		add(s.Test)
+	case *ast.ExpressionStatement:
+		add(s.Expression)
+	case *ast.ForInStatement:
+		add(s.Into)
+		add(s.Source)
+	case *ast.ForStatement:
+		add(s.Initializer)
+		add(s.Test)
+		add(s.Update)
+	case *ast.FunctionStatement:
+		add(s.Function)
+	case *ast.IfStatement:
+		add(s.Test)
+	case *ast.ReturnStatement:
+		add(s.Argument)
+	case *ast.SwitchStatement:
+		add(s.Discriminant)
+	case *ast.ThrowStatement:
+		add(s.Argument)
+	case *ast.VariableStatement:
+		for _, e := range s.List {
+			add(e)
+		}
+	case *ast.WhileStatement:
+		add(s.Test)
+	case *ast.WithStatement:
+		add(s.Object)
+	}
+
+	return expressions
+}
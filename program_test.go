@@ -0,0 +1,81 @@
+package astquery
+
+import (
+	"testing"
+
+	"github.com/robertkrimen/otto/ast"
+	"github.com/robertkrimen/otto/parser"
+)
+
+func parseProgram(t *testing.T, src string) *ast.Program {
+	program, err := parser.ParseFile(nil, "", src, 0)
+	if err != nil {
+		t.Fatalf("Unable to parse source, %v", err)
+	}
+
+	return program
+}
+
+func TestQuery_RunProgram(t *testing.T) {
+	program := parseProgram(t, `
+		f();
+		if (a) {
+			g();
+		} else {
+			for (var i = 0; i < 10; i++) {
+				while (b) { h(); }
+			}
+		}
+		switch (c) {
+		case 1:
+			i();
+		}
+		try {
+			j();
+		} catch (e) {
+			k();
+		} finally {
+			l();
+		}
+		function m() {
+			return n();
+		}
+		var o = function() {
+			p();
+		};
+	`)
+
+	matches := NewQuery().MustBeCall().RunProgram(program)
+
+	names := []string{"f", "g", "h", "i", "j", "k", "l", "n", "p"}
+	if len(matches) != len(names) {
+		t.Fatalf("Expected %v matches, got %v", len(names), len(matches))
+	}
+
+	for i, name := range names {
+		call := matches[i].Node.(*ast.CallExpression)
+		if callee := call.Callee.(*ast.Identifier); callee.Name != name {
+			t.Errorf("Match %v should be a call to %v, was %v", i, name, callee.Name)
+		}
+	}
+}
+
+func TestQuery_RunProgram_no_matches(t *testing.T) {
+	program := parseProgram(t, `var a = 1 + 2;`)
+
+	matches := NewQuery().MustBeCall().RunProgram(program)
+
+	if len(matches) != 0 {
+		t.Errorf("Expected no matches, got %v", len(matches))
+	}
+}
+
+func TestQuery_RunProgram_function_declaration(t *testing.T) {
+	program := parseProgram(t, `function f() { g(); }`)
+
+	matches := NewQuery().MustBeFunctionLiteral().RunProgram(program)
+
+	if len(matches) != 1 {
+		t.Errorf("Expected 1 match, got %v", len(matches))
+	}
+}
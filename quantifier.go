@@ -0,0 +1,158 @@
+package astquery
+
+import (
+	"github.com/robertkrimen/otto/ast"
+)
+
+// quantifier determines how many elements of a list must match
+type quantifier int
+
+const (
+	quantifyAll quantifier = iota
+	quantifyAny
+	quantifyNone
+)
+
+// quantifierQuery runs a query on the elements of a list like expression
+type quantifierQuery struct {
+	quantifier quantifier
+	query      *Query
+}
+
+func (qo *quantifierQuery) run(s *state, e ast.Node) (ast.Node, Captures, error) {
+	elements, ok := listElements(e)
+	if !ok {
+		return nil, nil, mismatch(e, "", "Node does not have a list of elements, was %T", e)
+	}
+
+	var captures Captures
+	switch qo.quantifier {
+	case quantifyAll:
+		for i, element := range elements {
+			result, err := s.sub(qo.query, element)
+			if err != nil {
+				return nil, nil, mismatch(e, "", "Element %v did not match", i).because(err)
+			}
+			captures = captures.merge(result.Captures)
+		}
+
+	case quantifyAny:
+		for _, element := range elements {
+			if result, err := s.sub(qo.query, element); err == nil {
+				return e, result.Captures, nil
+			}
+		}
+		return nil, nil, mismatch(e, "", "No element matched")
+
+	case quantifyNone:
+		for i, element := range elements {
+			if _, err := s.sub(qo.query, element); err == nil {
+				return nil, nil, mismatch(e, "", "Element %v matched", i)
+			}
+		}
+	}
+
+	return e, captures, nil
+}
+
+// listElements returns the elements of a list like node: the arguments of calls, the values
+// of array and object literals, the expressions of a sequence, the parameters of a function,
+// the statements of a block or case, the cases of a switch and the variables of a var statement.
+func listElements(e ast.Node) ([]ast.Node, bool) {
+	var elements []ast.Node
+	add := func(e ast.Expression) {
+		if e != nil {
+			elements = append(elements, e)
+		}
+	}
+	addStatement := func(s ast.Statement) {
+		if s != nil {
+			elements = append(elements, s)
+		}
+	}
+
+	switch t := e.(type) {
+	case *ast.ArrayLiteral:
+		for _, v := range t.Value {
+			add(v)
+		}
+	case *ast.CallExpression:
+		for _, a := range t.ArgumentList {
+			add(a)
+		}
+	case *ast.FunctionLiteral:
+		if t.ParameterList != nil {
+			for _, p := range t.ParameterList.List {
+				if p != nil {
+					add(p)
+				}
+			}
+		}
+	case *ast.NewExpression:
+		for _, a := range t.ArgumentList {
+			add(a)
+		}
+	case *ast.ObjectLiteral:
+		for _, p := range t.Value {
+			add(p.Value)
+		}
+	case *ast.SequenceExpression:
+		for _, s := range t.Sequence {
+			add(s)
+		}
+	case *ast.BlockStatement:
+		for _, s := range t.List {
+			addStatement(s)
+		}
+	case *ast.CaseStatement:
+		for _, s := range t.Consequent {
+			addStatement(s)
+		}
+	case *ast.SwitchStatement:
+		for _, c := range t.Body {
+			if c != nil {
+				addStatement(c)
+			}
+		}
+	case *ast.VariableStatement:
+		for _, v := range t.List {
+			add(v)
+		}
+	default:
+		return nil, false
+	}
+
+	return elements, true
+}
+
+// AllOf will only pass if the query matches every element of the expression.
+// Elements are the arguments of calls, the values of array and object literals,
+// the expressions of a sequence, the parameters of a function, the statements of
+// a block or case, the cases of a switch and the variables of a var statement.
+// An expression without elements passes.
+func (q *Query) AllOf(query *Query) *Query {
+	q.add("AllOf", &quantifierQuery{
+		quantifier: quantifyAll,
+		query:      query,
+	})
+	return q
+}
+
+// AnyOf will only pass if the query matches at least one element of the expression, see AllOf.
+func (q *Query) AnyOf(query *Query) *Query {
+	q.add("AnyOf", &quantifierQuery{
+		quantifier: quantifyAny,
+		query:      query,
+	})
+	return q
+}
+
+// NoneOf will only pass if the query matches no element of the expression, see AllOf.
+// Nothing is captured from the query.
+func (q *Query) NoneOf(query *Query) *Query {
+	q.add("NoneOf", &quantifierQuery{
+		quantifier: quantifyNone,
+		query:      query,
+	})
+	return q
+}
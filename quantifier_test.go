@@ -0,0 +1,83 @@
+package astquery
+
+import (
+	"testing"
+)
+
+func TestQuantifiers(t *testing.T) {
+	tests := []struct {
+		src      string
+		query    *Query
+		mustFail bool
+	}{
+		// Test 0
+		{`f(1, 2)`, NewQuery().MustBeCall().AllOf(NewQuery().AcceptNumbers(1)), false},
+
+		// Test 1
+		{`f(1, "2")`, NewQuery().MustBeCall().AllOf(NewQuery().AcceptNumbers(1)), true},
+
+		// Test 2
+		{`f(1, "2")`, NewQuery().MustBeCall().AnyOf(MustCompile("StringLiteral")), false},
+
+		// Test 3
+		{`f(1, 2)`, NewQuery().MustBeCall().AnyOf(MustCompile("StringLiteral")), true},
+
+		// Test 4
+		{`f()`, NewQuery().MustBeCall().AllOf(MustCompile("StringLiteral")), false},
+
+		// Test 5
+		{`f()`, NewQuery().MustBeCall().AnyOf(MustCompile("StringLiteral")), true},
+
+		// Test 6
+		{`[1, 2, a]`, NewQuery().NoneOf(MustCompile("StringLiteral")), false},
+
+		// Test 7
+		{`[1, "2", a]`, NewQuery().NoneOf(MustCompile("StringLiteral")), true},
+
+		// Test 8
+		{`({a: 1, b: true})`, NewQuery().MustBeObjectLiteral().AnyOf(NewQuery().AcceptBoolean(1)), false},
+
+		// Test 9
+		{`a, f(), b`, NewQuery().AnyOf(NewQuery().MustBeCall()), false},
+
+		// Test 10
+		{`new F(a, b)`, NewQuery().AllOf(MustCompile("Identifier")), false},
+
+		// Test 11
+		{`(function(a, _) {})`, NewQuery().MustBeFunctionLiteral().AnyOf(MustCompile("Identifier[name=_]")), false},
+
+		// Test 12
+		{`a + b`, NewQuery().AllOf(MustCompile("Identifier")), true},
+	}
+
+	for i, test := range tests {
+		matches, err := QuerySource(test.src, test.query)
+		if err != nil {
+			t.Errorf("Test %v failed, %v", i, err)
+			continue
+		}
+
+		if len(matches) == 0 && !test.mustFail {
+			t.Errorf("Test %v failed", i)
+		}
+		if len(matches) > 0 && test.mustFail {
+			t.Errorf("Test %v should have failed!", i)
+		}
+	}
+}
+
+func TestAllOf_captures(t *testing.T) {
+	matches, err := QuerySource(`f(1, a)`, NewQuery().MustBeCall().AllOf(NewQuery().Either(
+		NewQuery().AcceptNumbers(1).Capture("number"),
+		MustCompile("Identifier").Capture("identifier"))))
+	if err != nil {
+		t.Fatalf("Test failed, %v", err)
+	}
+
+	if len(matches) != 1 {
+		t.Fatalf("Expected 1 match, got %v", len(matches))
+	}
+	if matches[0].Capture("number") == nil || matches[0].Capture("identifier") == nil {
+		t.Errorf("Captures from all elements should be kept, was %v", matches[0].Captures)
+	}
+}
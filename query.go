@@ -1,15 +1,20 @@
 package astquery
 
 import (
-	"fmt"
 	"github.com/robertkrimen/otto/ast"
 	"github.com/robertkrimen/otto/token"
 )
 
-// Query defines the basic structure for a query
+// Query defines the basic structure for a query.
+//
+// Building a query is not safe for concurrent use, but once built, a query can be evaluated
+// by any number of goroutines at once, see Evaluate. Run and RunStatement keep the captures
+// and the collected expression of the last run in the query, and are not safe for concurrent use.
 type Query struct {
 	operations []QLOperation
-	Collected  ast.Expression
+	names      []string
+	Collected  ast.Node
+	captures   Captures
 }
 
 // NewQuery returns a new query
@@ -17,23 +22,96 @@ func NewQuery() *Query {
 	return &Query{}
 }
 
-// Run runs the query given the ast expression
-func (ql *Query) Run(expression ast.Expression) error {
-	for _, q := range ql.operations {
-		err := q.run(expression)
+// add appends an operation given the name of the method adding it.
+func (ql *Query) add(name string, operation QLOperation) *Query {
+	ql.operations = append(ql.operations, operation)
+	ql.names = append(ql.names, name)
+	return ql
+}
+
+// Result is the outcome of a query matching a node
+type Result struct {
+	// Node is the node the query ended at
+	Node ast.Node
+
+	// Collected is the node the first operation ended at
+	Collected ast.Node
+
+	Captures Captures
+}
+
+// state holds everything a single run of a query, including its sub queries, needs besides the node.
+// Operations keep no state of their own, so a query can be run concurrently with separate states.
+type state struct {
+	// parents of the nodes in the program the query is run on, if any
+	parents parents
+
+	// tracer receives the evaluated operations, if any, depth is the nesting of the running query
+	tracer Tracer
+	depth  int
+}
+
+// sub runs a sub query of the running query.
+func (s *state) sub(q *Query, node ast.Node) (*Result, error) {
+	s.depth++
+	defer func() { s.depth-- }()
+
+	return q.eval(s, node)
+}
+
+// eval runs the query on the node given the state of the run.
+func (ql *Query) eval(s *state, node ast.Node) (*Result, error) {
+	result := &Result{}
+	for i, q := range ql.operations {
+		output, captures, err := q.run(s, node)
 		if err != nil {
-			return err
+			if m, isMismatch := err.(*MismatchError); isMismatch && m.Operation == "" {
+				m.Operation = ql.names[i]
+			}
+			s.trace(ql.names[i], node, nil, err)
+			return nil, err
 		}
+		s.trace(ql.names[i], node, output, nil)
 
-		expression = q.get()
-		if ql.Collected == nil {
-			ql.Collected = expression
+		result.Captures = result.Captures.merge(captures)
+		node = output
+		if i == 0 {
+			result.Collected = node
 		}
 	}
 
+	result.Node = node
+	return result, nil
+}
+
+// Evaluate runs the query given the ast node and returns the result if the query matches.
+// Unlike Run, the query is not modified, so it can be evaluated by several goroutines at once.
+func (ql *Query) Evaluate(node ast.Node) (*Result, error) {
+	return ql.eval(&state{}, node)
+}
+
+// keep stores the result of a run in the query, for Captured and Collected.
+func (ql *Query) keep(result *Result, err error) error {
+	ql.captures = nil
+	if err != nil {
+		return err
+	}
+
+	ql.captures = result.Captures
+	if ql.Collected == nil {
+		ql.Collected = result.Collected
+	}
+
 	return nil
 }
 
+// Run runs the query given the ast node, which is usually an expression, but may also be a statement
+func (ql *Query) Run(expression ast.Node) error {
+	return ql.keep(ql.Evaluate(expression))
+}
+
+// RunStatement runs the query on the expressions of an expression, return or var statement,
+// and on the statement itself otherwise, see Run.
 func (ql *Query) RunStatement(statement ast.Statement) error {
 	switch s := statement.(type) {
 	case *ast.ExpressionStatement:
@@ -50,7 +128,7 @@ func (ql *Query) RunStatement(statement ast.Statement) error {
 
 		return nil
 	default:
-		return fmt.Errorf("Unsupported statement: %T", statement)
+		return ql.Run(statement)
 	}
 }
 
@@ -59,20 +137,19 @@ func (ql *Query) Collect() *Query {
 	return ql
 }
 
-// QLOperation specifies a query operation
+// QLOperation specifies a query operation. Given the node, an operation returns the node the query
+// continues from and what it captured, or why the node was rejected. Operations must not keep state
+// between runs, anything a run needs is in the state.
 type QLOperation interface {
-	run(ast.Expression) error
-	get() ast.Expression
+	run(s *state, node ast.Node) (ast.Node, Captures, error)
 }
 
 // operatorQuery filters expressions based on operators
 type operatorQuery struct {
-	operators  []token.Token
-	expression ast.Expression
+	operators []token.Token
 }
 
-func (qo *operatorQuery) run(e ast.Expression) error {
-	qo.expression = e
+func (qo *operatorQuery) run(s *state, e ast.Node) (ast.Node, Captures, error) {
 	var operator token.Token
 	switch t := e.(type) {
 	case *ast.AssignExpression:
@@ -85,142 +162,171 @@ func (qo *operatorQuery) run(e ast.Expression) error {
 		operator = t.Operator
 
 	default:
-		return fmt.Errorf("Expression not compatible with operators")
+		return nil, nil, mismatch(e, "", "Expression not compatible with operators, was %T", e)
 	}
 
-	found := false
 	for _, op := range qo.operators {
 		if op == operator {
-			found = true
-			break
+			return e, nil, nil
 		}
 	}
 
-	if !found {
-		return fmt.Errorf("Invalid operator for expression, %v", operator)
-	}
-
-	return nil
-}
-
-func (qo *operatorQuery) get() ast.Expression {
-	return qo.expression
+	return nil, nil, mismatch(e, "", "Invalid operator for expression, %v", operator)
 }
 
 // HasOperator filters expressions given the set of operators.
 func (q *Query) HasOperator(operators ...token.Token) *Query {
-	q.operations = append(q.operations, &operatorQuery{
+	q.add("HasOperator", &operatorQuery{
 		operators: operators,
 	})
 	return q
 }
 
 func (q *Query) RightSide(query *Query) *Query {
-	q.operations = append(q.operations, &rightSideQuery{
+	q.add("RightSide", &rightSideQuery{
 		query: query,
 	})
 	return q
 }
 
 type rightSideQuery struct {
-	expression ast.Expression
-	query      *Query
+	query *Query
 }
 
-func (qo *rightSideQuery) run(e ast.Expression) error {
-	qo.expression = e
+func (qo *rightSideQuery) run(s *state, e ast.Node) (ast.Node, Captures, error) {
+	var right ast.Expression
 	switch n := e.(type) {
 	case *ast.AssignExpression:
-		return qo.query.Run(n.Right)
+		right = n.Right
 	case *ast.BinaryExpression:
-		return qo.query.Run(n.Right)
+		right = n.Right
 	case *ast.VariableExpression:
-		return qo.query.Run(n.Initializer)
+		right = n.Initializer
 	default:
-		return fmt.Errorf("Expression is not compatible with right side queries.")
+		return nil, nil, mismatch(e, "", "Expression is not compatible with right side queries, was %T", e)
 	}
+
+	result, err := s.sub(qo.query, right)
+	if err != nil {
+		return nil, nil, mismatch(e, "", "Right side did not match").because(err)
+	}
+
+	return e, result.Captures, nil
+}
+
+// LeftSide runs the query on the target of an assignment, the left operand of a binary expression
+// or the name of a variable.
+func (q *Query) LeftSide(query *Query) *Query {
+	q.add("LeftSide", &leftSideQuery{
+		query: query,
+	})
+	return q
+}
+
+type leftSideQuery struct {
+	query *Query
 }
 
-func (qo *rightSideQuery) get() ast.Expression {
-	return qo.expression
+func (qo *leftSideQuery) run(s *state, e ast.Node) (ast.Node, Captures, error) {
+	var left ast.Expression
+	switch n := e.(type) {
+	case *ast.AssignExpression:
+		left = n.Left
+	case *ast.BinaryExpression:
+		left = n.Left
+	case *ast.VariableExpression:
+		left = variableIdentifier(n)
+	default:
+		return nil, nil, mismatch(e, "", "Expression is not compatible with left side queries, was %T", e)
+	}
+
+	result, err := s.sub(qo.query, left)
+	if err != nil {
+		return nil, nil, mismatch(e, "", "Left side did not match").because(err)
+	}
+
+	return e, result.Captures, nil
 }
 
 type either struct {
-	expression ast.Expression
-	queries    []*Query
+	queries []*Query
 }
 
-func (qo *either) run(e ast.Expression) error {
+func (qo *either) run(s *state, e ast.Node) (ast.Node, Captures, error) {
 	errors := make([]error, len(qo.queries))
-	failed := true
 	for i, q := range qo.queries {
-		err := q.Run(e)
+		result, err := s.sub(q, e)
 		if err == nil {
-			failed = false
-			break
+			return e, result.Captures, nil
 		}
 
 		errors[i] = err
 	}
 
-	if failed {
-		return fmt.Errorf("Failed either: %v", errors)
-	}
+	return nil, nil, mismatch(e, "", "Failed either").because(errors...)
+}
 
-	qo.expression = e
-	return nil
+func (q *Query) Either(queries ...*Query) *Query {
+	q.add("Either", &either{
+		queries: queries,
+	})
+	return q
 }
 
-func (qo *either) get() ast.Expression {
-	return qo.expression
+// not passes if the sub query fails
+type not struct {
+	query *Query
 }
 
-func (q *Query) Either(queries ...*Query) *Query {
-	q.operations = append(q.operations, &either{
-		queries: queries,
+func (qo *not) run(s *state, e ast.Node) (ast.Node, Captures, error) {
+	if _, err := s.sub(qo.query, e); err == nil {
+		return nil, nil, mismatch(e, "", "Expression matched negated query")
+	}
+
+	return e, nil, nil
+}
+
+// Not will only pass if the query does not match the expression.
+// Nothing is captured from the negated query.
+func (q *Query) Not(query *Query) *Query {
+	q.add("Not", &not{
+		query: query,
 	})
 	return q
 }
 
 // eitherSideQuery will try to run the two provided queries on the binary expression in both order.
 type eitherSideQuery struct {
-	expression ast.Expression
 	one, other *Query
 }
 
-func (qo *eitherSideQuery) run(e ast.Expression) error {
-
+func (qo *eitherSideQuery) run(s *state, e ast.Node) (ast.Node, Captures, error) {
 	// Must be binary
 	binary, isBinary := e.(*ast.BinaryExpression)
 	if !isBinary {
-		return fmt.Errorf("Expression is not binary")
+		return nil, nil, mismatch(e, "BinaryExpression", "Expression is not binary, was %T", e)
 	}
 
 	// First
-	err1 := qo.one.Run(binary.Left)
-	err2 := qo.other.Run(binary.Right)
+	result1, err1 := s.sub(qo.one, binary.Left)
+	result2, err2 := s.sub(qo.other, binary.Right)
 	if err1 != nil || err2 != nil {
-		err1 := qo.one.Run(binary.Right)
-		err2 := qo.other.Run(binary.Left)
+		result1, err1 = s.sub(qo.one, binary.Right)
+		result2, err2 = s.sub(qo.other, binary.Left)
 
 		if err1 != nil || err2 != nil {
-			return fmt.Errorf("Expression is not compatible, left: %v, right: %v", err1, err2)
+			return nil, nil, mismatch(e, "", "Expression is not compatible with either order").because(err1, err2)
 		}
 	}
 
-	qo.expression = e
-
-	return nil
-}
-
-func (qo *eitherSideQuery) get() ast.Expression {
-	return qo.expression
+	var captures Captures
+	return e, captures.merge(result1.Captures).merge(result2.Captures), nil
 }
 
 // OneSideOtherSide will run the queries on both operands in a binary expression in both order.
 // If the first order doesn't work the other is tried.
 func (q *Query) OneSideOtherSide(one *Query, other *Query) *Query {
-	q.operations = append(q.operations, &eitherSideQuery{
+	q.add("OneSideOtherSide", &eitherSideQuery{
 		one:   one,
 		other: other,
 	})
@@ -229,27 +335,22 @@ func (q *Query) OneSideOtherSide(one *Query, other *Query) *Query {
 
 // numberQuery will determine if a part of the tree is solely numbers
 type numberQuery struct {
-	depth      int
-	expression ast.Expression
+	depth int
 }
 
-func (qo *numberQuery) run(expression ast.Expression) error {
-	qo.expression = expression
-	ok := VerifyExpression(expression, qo.depth, newOnlyNumberVerifier())
+func (qo *numberQuery) run(s *state, expression ast.Node) (ast.Node, Captures, error) {
+	e, isExpression := expression.(ast.Expression)
+	ok := isExpression && VerifyExpression(e, qo.depth, newOnlyNumberVerifier())
 	if !ok {
-		return fmt.Errorf("Expression does not only contain numbers")
+		return nil, nil, mismatch(expression, "", "Expression does not only contain numbers")
 	}
 
-	return nil
-}
-
-func (qo *numberQuery) get() ast.Expression {
-	return qo.expression
+	return expression, nil, nil
 }
 
 // AcceptNumbers will only pass if the subtree is solely numbers.
 func (q *Query) AcceptNumbers(depth int) *Query {
-	q.operations = append(q.operations, &numberQuery{
+	q.add("AcceptNumbers", &numberQuery{
 		depth: depth,
 	})
 	return q
@@ -257,37 +358,37 @@ func (q *Query) AcceptNumbers(depth int) *Query {
 
 // operandsQuery will run a query on all possible operands
 type operandsQuery struct {
-	expression ast.Expression
-	query      *Query
+	query *Query
 }
 
-func (qo *operandsQuery) run(expression ast.Expression) error {
+func (qo *operandsQuery) run(s *state, expression ast.Node) (ast.Node, Captures, error) {
 	switch t := expression.(type) {
 	case *ast.BinaryExpression:
-		err1 := qo.query.Run(t.Left)
-		err2 := qo.query.Run(t.Right)
+		result1, err1 := s.sub(qo.query, t.Left)
+		result2, err2 := s.sub(qo.query, t.Right)
 		if err1 != nil || err2 != nil {
-			return fmt.Errorf("Binary operands where not compatible, left: %v, right: %v", err1, err2)
+			return nil, nil, mismatch(expression, "", "Binary operands where not compatible").because(err1, err2)
 		}
+
+		var captures Captures
+		return expression, captures.merge(result1.Captures).merge(result2.Captures), nil
 	case *ast.UnaryExpression:
-		err := qo.query.Run(t.Operand)
-		return err
+		result, err := s.sub(qo.query, t.Operand)
+		if err != nil {
+			return nil, nil, mismatch(expression, "", "Unary operand was not compatible").because(err)
+		}
+
+		return expression, result.Captures, nil
 	default:
-		return fmt.Errorf("Expression does not have one operand")
+		return nil, nil, mismatch(expression, "", "Expression does not have operands, was %T", expression)
 	}
-
-	return nil
-}
-
-func (qo *operandsQuery) get() ast.Expression {
-	return qo.expression
 }
 
 // Operands will run the query on all possible operands.
 // Unary - one operand
 // Binary - two operands
 func (q *Query) Operands(query *Query) *Query {
-	q.operations = append(q.operations, &operandsQuery{
+	q.add("Operands", &operandsQuery{
 		query: query,
 	})
 	return q
@@ -224,7 +224,7 @@ func TestQLCall(t *testing.T) {
 		ArgumentList: nil,
 	}
 	statement := &ast.ExpressionStatement{
-		call,
+		Expression: call,
 	}
 
 	err := NewQuery().MustBeCall().RunStatement(statement)
@@ -247,7 +247,7 @@ func TestQLCall2(t *testing.T) {
 		ArgumentList: nil,
 	}
 	statement := &ast.ExpressionStatement{
-		call2,
+		Expression: call2,
 	}
 
 	inspector := NewQuery().MustBeCallD(true).Collect()
@@ -410,3 +410,39 @@ func TestEither(t *testing.T) {
 		t.Errorf("Failed: %v", err)
 	}
 }
+
+func TestNot(t *testing.T) {
+	program := parseProgram(t, `a + 1; a + "b"; "c" + 2; a - "d";`)
+
+	q := NewQuery().MustBeBinary().HasOperator(token.PLUS).Operands(NewQuery().Not(MustCompile("StringLiteral")))
+
+	matches := q.RunProgram(program)
+	if len(matches) != 1 {
+		t.Errorf("Expected 1 match, got %v", len(matches))
+	}
+}
+
+func TestNot_captures(t *testing.T) {
+	q := NewQuery().Not(NewQuery().Capture("negated").MustBeCall()).Capture("identifier")
+
+	err := q.Run(&ast.Identifier{Name: "a"})
+	if err != nil {
+		t.Fatalf("Test failed, %v", err)
+	}
+
+	if q.Captured("negated") != nil {
+		t.Errorf("Negated query should not capture, was %v", q.Captured("negated"))
+	}
+	if q.Captured("identifier") == nil {
+		t.Errorf("Identifier should be captured")
+	}
+}
+
+func TestRunStatement_other_statements(t *testing.T) {
+	program := parseProgram(t, `if (a) { f(); }`)
+
+	err := NewQuery().MustBeIf().Test(NewQuery().MustBeIdentifier()).RunStatement(program.Body[0])
+	if err != nil {
+		t.Errorf("Test failed, %v", err)
+	}
+}
@@ -0,0 +1,282 @@
+package astquery
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/robertkrimen/otto/parser"
+)
+
+// Severity is how serious a finding of a rule is
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+// ParseSeverity returns the severity given its name, e.g. "warning".
+func ParseSeverity(name string) (Severity, error) {
+	switch severity := Severity(strings.ToLower(name)); severity {
+	case SeverityError, SeverityWarning, SeverityInfo:
+		return severity, nil
+	default:
+		return "", fmt.Errorf("Invalid severity %q, must be error, warning or info", name)
+	}
+}
+
+// Rule is a named query with the metadata needed to report its matches
+type Rule struct {
+	// ID identifies the rule within its rule set, e.g. "no-console"
+	ID string
+
+	Description string
+	Severity    Severity
+
+	// Message is reported for every match. It may reference captures by name, e.g. "avoid calling {{callee}}",
+	// which are replaced by the source of the captured node.
+	Message string
+
+	// DocURL links to the documentation of the rule, if any
+	DocURL string
+
+	Tags []string
+
+	Query *Query
+}
+
+// Finding is a match of a rule
+type Finding struct {
+	Rule  *Rule
+	Match *Match
+
+	// Message is the message of the rule with the captures of the match filled in
+	Message string
+}
+
+var templateCapture = regexp.MustCompile(`{{\s*([^{}\s]+)\s*}}`)
+
+// Format returns the message of the rule for the match. A reference to a capture the match does not have is left as is.
+func (r *Rule) Format(m *Match) string {
+	return templateCapture.ReplaceAllStringFunc(r.Message, func(reference string) string {
+		name := templateCapture.FindStringSubmatch(reference)[1]
+		node := m.Capture(name)
+		if node == nil {
+			return reference
+		}
+
+		if text := m.Text(node); text != "" {
+			return text
+		}
+		if text, ok := attribute(node, "name"); ok {
+			return text
+		}
+
+		return kindOf(node)
+	})
+}
+
+// Finding returns the finding of the rule for the match.
+func (r *Rule) Finding(m *Match) *Finding {
+	return &Finding{
+		Rule:    r,
+		Match:   m,
+		Message: r.Format(m),
+	}
+}
+
+// HasTag reports whether the rule is tagged with the tag.
+func (r *Rule) HasTag(tag string) bool {
+	for _, t := range r.Tags {
+		if t == tag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validate returns why the rule cannot be added to a rule set, if it cannot.
+func (r *Rule) validate() error {
+	if r.ID == "" {
+		return fmt.Errorf("Rule does not have an id")
+	}
+	if r.Query == nil {
+		return fmt.Errorf("Rule %v does not have a query", r.ID)
+	}
+	switch r.Severity {
+	case SeverityError, SeverityWarning, SeverityInfo:
+	default:
+		return fmt.Errorf("Rule %v: Invalid severity %q, must be error, warning or info", r.ID, r.Severity)
+	}
+
+	return nil
+}
+
+// RuleSet is a versioned collection of rules with unique ids
+type RuleSet struct {
+	Name    string
+	Version string
+
+	rules []*Rule
+	ids   map[string]*Rule
+}
+
+// NewRuleSet returns an empty rule set
+func NewRuleSet(name, version string) *RuleSet {
+	return &RuleSet{
+		Name:    name,
+		Version: version,
+		ids:     map[string]*Rule{},
+	}
+}
+
+// Add adds the rules to the set. No rule is added if one of them is invalid, i.e. has no id or query,
+// has an invalid severity, or has the id of another rule. Severities are case sensitive, see ParseSeverity.
+func (rs *RuleSet) Add(rules ...*Rule) error {
+	ids := map[string]bool{}
+	for _, r := range rules {
+		if err := r.validate(); err != nil {
+			return err
+		}
+		if rs.ids[r.ID] != nil || ids[r.ID] {
+			return fmt.Errorf("Rule %v is already defined", r.ID)
+		}
+		ids[r.ID] = true
+	}
+
+	// The rule set may not have been created by NewRuleSet
+	if rs.ids == nil {
+		rs.ids = map[string]*Rule{}
+	}
+	for _, r := range rules {
+		rs.rules = append(rs.rules, r)
+		rs.ids[r.ID] = r
+	}
+
+	return nil
+}
+
+// Rule returns the rule with the id, or nil.
+func (rs *RuleSet) Rule(id string) *Rule {
+	return rs.ids[id]
+}
+
+// Rules returns the rules in the order they were added.
+func (rs *RuleSet) Rules() []*Rule {
+	return append([]*Rule(nil), rs.rules...)
+}
+
+// Tagged returns the rules having the tag, in the order they were added.
+func (rs *RuleSet) Tagged(tag string) []*Rule {
+	var rules []*Rule
+	for _, r := range rs.rules {
+		if r.HasTag(tag) {
+			rules = append(rules, r)
+		}
+	}
+
+	return rules
+}
+
+// Scanner returns a scanner running every rule, with results named by rule id.
+func (rs *RuleSet) Scanner() *Scanner {
+	scanner := NewScanner()
+	for _, r := range rs.rules {
+		scanner.Add(r.ID, r.Query)
+	}
+
+	return scanner
+}
+
+// Findings parses the source and returns the findings of every rule, rule by rule, each in source order.
+// Like the scanner, the rules are matched at every node, see FindAll.
+func (rs *RuleSet) Findings(src string) ([]*Finding, error) {
+	program, err := parser.ParseFile(nil, "", src, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []*Finding
+	for _, r := range rs.rules {
+		for _, m := range r.Query.FindAll(program) {
+			findings = append(findings, r.Finding(m))
+		}
+	}
+
+	return findings, nil
+}
+
+// ruleFile is the JSON form of a rule set, with the queries of the rules given as selectors, see Compile
+type ruleFile struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Rules   []struct {
+		ID          string   `json:"id"`
+		Description string   `json:"description"`
+		Severity    string   `json:"severity"`
+		Message     string   `json:"message"`
+		DocURL      string   `json:"docUrl"`
+		Tags        []string `json:"tags"`
+		Selector    string   `json:"selector"`
+	} `json:"rules"`
+}
+
+// ReadRuleSet reads a rule set in JSON, e.g.
+//
+//	{
+//		"name": "core",
+//		"version": "1.0.0",
+//		"rules": [{
+//			"id": "no-debugger",
+//			"severity": "error",
+//			"message": "remove the debugger statement",
+//			"selector": "DebuggerStatement"
+//		}]
+//	}
+//
+// The severity of a rule defaults to warning, and its message to its description.
+func ReadRuleSet(r io.Reader) (*RuleSet, error) {
+	var f ruleFile
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&f); err != nil {
+		return nil, err
+	}
+
+	rs := NewRuleSet(f.Name, f.Version)
+	for _, fr := range f.Rules {
+		query, err := Compile(fr.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("Rule %v: %v", fr.ID, err)
+		}
+
+		rule := &Rule{
+			ID:          fr.ID,
+			Description: fr.Description,
+			Severity:    SeverityWarning,
+			Message:     fr.Message,
+			DocURL:      fr.DocURL,
+			Tags:        fr.Tags,
+			Query:       query,
+		}
+		if fr.Severity != "" {
+			if rule.Severity, err = ParseSeverity(fr.Severity); err != nil {
+				return nil, fmt.Errorf("Rule %v: %v", fr.ID, err)
+			}
+		}
+		if rule.Message == "" {
+			rule.Message = rule.Description
+		}
+
+		if err := rs.Add(rule); err != nil {
+			return nil, err
+		}
+	}
+
+	return rs, nil
+}
@@ -0,0 +1,184 @@
+package astquery
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseSeverity(t *testing.T) {
+	tests := []struct {
+		name     string
+		severity Severity
+		valid    bool
+	}{
+		// Test 0
+		{"error", SeverityError, true},
+
+		// Test 1
+		{"Warning", SeverityWarning, true},
+
+		// Test 2
+		{"info", SeverityInfo, true},
+
+		// Test 3
+		{"fatal", "", false},
+	}
+
+	for i, test := range tests {
+		severity, err := ParseSeverity(test.name)
+		if (err == nil) != test.valid || severity != test.severity {
+			t.Errorf("Test %v failed, got %q, %v", i, severity, err)
+		}
+	}
+}
+
+func TestRuleSet_Findings(t *testing.T) {
+	rules := NewRuleSet("core", "1.0.0")
+	err := rules.Add(&Rule{
+		ID:       "no-console",
+		Severity: SeverityWarning,
+		Message:  "avoid calling {{callee}} with {{ arg }}, {{missing}}",
+		Tags:     []string{"debug"},
+		Query:    NewQuery().MustBeCall().CalleePath("console.*").Arg(0, NewQuery().Capture("arg")).CallMustHaveIdentifier().Capture("callee"),
+	}, &Rule{
+		ID:       "no-eval",
+		Severity: SeverityError,
+		Message:  "eval is evil",
+		Query:    NewQuery().MustBeCall().CalleePath("eval"),
+	})
+	if err != nil {
+		t.Fatalf("Test failed, %v", err)
+	}
+
+	findings, err := rules.Findings("eval(s);\nconsole.log(\"a\" + b);")
+	if err != nil {
+		t.Fatalf("Test failed, %v", err)
+	}
+
+	expected := []struct {
+		rule    string
+		message string
+		line    int
+	}{
+		{"no-console", `avoid calling log with "a" + b, {{missing}}`, 2},
+		{"no-eval", "eval is evil", 1},
+	}
+
+	if len(findings) != len(expected) {
+		t.Fatalf("Expected %v findings, got %v", len(expected), len(findings))
+	}
+	for i, e := range expected {
+		f := findings[i]
+		if f.Rule.ID != e.rule || f.Message != e.message || f.Match.Line != e.line {
+			t.Errorf("Finding %v not correct, was %v %q at line %v", i, f.Rule.ID, f.Message, f.Match.Line)
+		}
+	}
+
+	if rules.Rule("no-eval") == nil || rules.Rule("missing") != nil {
+		t.Errorf("Rule lookup not correct")
+	}
+	if tagged := rules.Tagged("debug"); len(tagged) != 1 || tagged[0].ID != "no-console" {
+		t.Errorf("Tagged rules not correct, was %v", tagged)
+	}
+}
+
+func TestRuleSet_Add_invalid(t *testing.T) {
+	tests := []*Rule{
+		// Test 0, no id
+		{Severity: SeverityError, Query: NewQuery()},
+
+		// Test 1, no query
+		{ID: "a", Severity: SeverityError},
+
+		// Test 2, invalid severity
+		{ID: "a", Severity: "fatal", Query: NewQuery()},
+
+		// Test 3, duplicate id
+		{ID: "existing", Severity: SeverityInfo, Query: NewQuery()},
+
+		// Test 4, severities are not normalized
+		{ID: "a", Severity: "ERROR", Query: NewQuery()},
+	}
+
+	for i, test := range tests {
+		rules := NewRuleSet("core", "1.0.0")
+		if err := rules.Add(&Rule{ID: "existing", Severity: SeverityInfo, Query: NewQuery()}); err != nil {
+			t.Fatalf("Test %v failed, %v", i, err)
+		}
+
+		if err := rules.Add(&Rule{ID: "valid", Severity: SeverityInfo, Query: NewQuery()}, test); err == nil {
+			t.Errorf("Test %v should have failed", i)
+		}
+		if len(rules.Rules()) != 1 {
+			t.Errorf("Test %v should not add any rule", i)
+		}
+	}
+}
+
+func TestRuleSet_Add_literal(t *testing.T) {
+	rules := &RuleSet{Name: "core"}
+	if err := rules.Add(&Rule{ID: "a", Severity: SeverityError, Query: NewQuery()}); err != nil {
+		t.Fatalf("Test failed, %v", err)
+	}
+
+	if rules.Rule("a") == nil || len(rules.Rules()) != 1 {
+		t.Errorf("Rule a should have been added")
+	}
+}
+
+func TestReadRuleSet(t *testing.T) {
+	rules, err := ReadRuleSet(strings.NewReader(`{
+		"name": "core",
+		"version": "1.2.0",
+		"rules": [
+			{"id": "no-eval", "severity": "error", "message": "avoid eval", "docUrl": "https://example.com/no-eval", "selector": "CallExpression > Identifier[name=eval]"},
+			{"id": "no-this", "description": "this is confusing", "tags": ["style"], "selector": "ThisExpression"}
+		]
+	}`))
+	if err != nil {
+		t.Fatalf("Test failed, %v", err)
+	}
+
+	if rules.Name != "core" || rules.Version != "1.2.0" || len(rules.Rules()) != 2 {
+		t.Fatalf("Rule set not correct, was %+v", rules)
+	}
+
+	eval := rules.Rule("no-eval")
+	if eval.Severity != SeverityError || eval.Message != "avoid eval" || eval.DocURL != "https://example.com/no-eval" {
+		t.Errorf("Rule no-eval not correct, was %+v", eval)
+	}
+	this := rules.Rule("no-this")
+	if this.Severity != SeverityWarning || this.Message != "this is confusing" || !this.HasTag("style") {
+		t.Errorf("Rule no-this not correct, was %+v", this)
+	}
+
+	findings, err := rules.Findings(`eval(this.s);`)
+	if err != nil {
+		t.Fatalf("Test failed, %v", err)
+	}
+	if len(findings) != 2 || findings[0].Rule != eval || findings[1].Rule != this {
+		t.Errorf("Findings not correct, was %v", findings)
+	}
+}
+
+func TestReadRuleSet_invalid(t *testing.T) {
+	tests := []string{
+		// Test 0
+		`{"rules": [{"id": "a", "selector": "Call["}]}`,
+
+		// Test 1
+		`{"rules": [{"id": "a", "severity": "fatal", "selector": "CallExpression"}]}`,
+
+		// Test 2
+		`{"rules": [{"id": "a", "selector": "CallExpression"}, {"id": "a", "selector": "CallExpression"}]}`,
+
+		// Test 3
+		`{"rules": [{"id": "a", "query": "CallExpression"}]}`,
+	}
+
+	for i, test := range tests {
+		if _, err := ReadRuleSet(strings.NewReader(test)); err == nil {
+			t.Errorf("Test %v should have failed", i)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package astquery
+
+import (
+	"encoding/json"
+	"io"
+	"path/filepath"
+
+	"github.com/robertkrimen/otto/file"
+)
+
+// The SARIF 2.1.0 log written by WriteSARIF, limited to the properties used
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name    string      `json:"name"`
+	Version string      `json:"version,omitempty"`
+	Rules   []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string               `json:"id"`
+	ShortDescription     *sarifMessage        `json:"shortDescription,omitempty"`
+	HelpURI              string               `json:"helpUri,omitempty"`
+	DefaultConfiguration sarifConfiguration   `json:"defaultConfiguration"`
+	Properties           *sarifRuleProperties `json:"properties,omitempty"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifRuleProperties struct {
+	Tags []string `json:"tags"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int           `json:"startLine"`
+	StartColumn int           `json:"startColumn"`
+	EndLine     int           `json:"endLine,omitempty"`
+	EndColumn   int           `json:"endColumn,omitempty"`
+	Snippet     *sarifMessage `json:"snippet,omitempty"`
+}
+
+// sarifLevel returns the SARIF level of a severity.
+func sarifLevel(severity Severity) string {
+	switch severity {
+	case SeverityError:
+		return "error"
+	case SeverityInfo:
+		return "note"
+	default:
+		return "warning"
+	}
+}
+
+// sarifColumn converts the column of the source index idx, which otto counts in bytes, to UTF-16 code
+// units as SARIF expects by default.
+func sarifColumn(m *Match, idx file.Idx, column int) int {
+	if m.file == nil || column < 1 {
+		return column
+	}
+
+	src := m.file.Source()
+	end := int(idx) - m.file.Base()
+	start := end - (column - 1)
+	if start < 0 || end > len(src) {
+		return column
+	}
+
+	// Runes outside the basic multilingual plane are encoded as surrogate pairs
+	units := 1
+	for _, r := range src[start:end] {
+		if r >= 0x10000 {
+			units += 2
+		} else {
+			units++
+		}
+	}
+
+	return units
+}
+
+// WriteSARIF writes the findings of the rules as a SARIF 2.1.0 log with a single run. The tool
+// is named after the rule set, astquery if it has no name, and describes every rule of the set.
+// Findings are located by the file, start and end of the matched node, with its source as snippet.
+// Columns are counted in UTF-16 code units.
+func WriteSARIF(w io.Writer, rules *RuleSet, findings []*Finding) error {
+	driver := sarifDriver{
+		Name:    rules.Name,
+		Version: rules.Version,
+		Rules:   []sarifRule{},
+	}
+	if driver.Name == "" {
+		driver.Name = "astquery"
+	}
+
+	indexes := map[*Rule]int{}
+	for i, r := range rules.Rules() {
+		indexes[r] = i
+		rule := sarifRule{
+			ID:                   r.ID,
+			HelpURI:              r.DocURL,
+			DefaultConfiguration: sarifConfiguration{Level: sarifLevel(r.Severity)},
+		}
+		if r.Description != "" {
+			rule.ShortDescription = &sarifMessage{Text: r.Description}
+		}
+		if len(r.Tags) > 0 {
+			rule.Properties = &sarifRuleProperties{Tags: r.Tags}
+		}
+		driver.Rules = append(driver.Rules, rule)
+	}
+
+	results := []sarifResult{}
+	for _, f := range findings {
+		index, known := indexes[f.Rule]
+		if !known {
+			index = -1
+		}
+
+		// Every result must have a message
+		message := f.Message
+		if message == "" {
+			message = f.Rule.Description
+		}
+		if message == "" {
+			message = f.Rule.ID
+		}
+
+		location := sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(f.Match.Filename)},
+		}
+		if f.Match.Line > 0 {
+			location.Region = &sarifRegion{
+				StartLine:   f.Match.Line,
+				StartColumn: sarifColumn(f.Match, f.Match.Idx0, f.Match.Column),
+				EndLine:     f.Match.EndLine,
+				EndColumn:   sarifColumn(f.Match, f.Match.Idx1, f.Match.EndColumn),
+			}
+			if snippet := f.Match.Text(f.Match.Node); snippet != "" {
+				location.Region.Snippet = &sarifMessage{Text: snippet}
+			}
+		}
+
+		results = append(results, sarifResult{
+			RuleID:    f.Rule.ID,
+			RuleIndex: index,
+			Level:     sarifLevel(f.Rule.Severity),
+			Message:   sarifMessage{Text: message},
+			Locations: []sarifLocation{{PhysicalLocation: location}},
+		})
+	}
+
+	log := sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs: []sarifRun{{
+			Tool:    sarifTool{Driver: driver},
+			Results: results,
+		}},
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(log)
+}
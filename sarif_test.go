@@ -0,0 +1,72 @@
+package astquery
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"sort"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func TestWriteSARIF(t *testing.T) {
+	rules := NewRuleSet("example", "1.0.0")
+	err := rules.Add(&Rule{
+		ID:          "no-console",
+		Description: "Calls to console should not be committed",
+		Severity:    SeverityWarning,
+		Message:     "avoid calling console.{{method}}",
+		DocURL:      "https://example.com/rules/no-console",
+		Tags:        []string{"debug"},
+		Query:       NewQuery().MustBeCall().CalleePath("console.*").CallMustHaveIdentifier().Capture("method"),
+	}, &Rule{
+		ID:       "no-var",
+		Severity: SeverityInfo,
+		Query:    NewQuery().MustBeVariable(),
+	})
+	if err != nil {
+		t.Fatalf("Test failed, %v", err)
+	}
+
+	var findings []*Finding
+	for result := range rules.Scanner().Scan(context.Background(), "testdata/scan/a.js", "testdata/scan/lib/b.js", "testdata/unicode.js") {
+		if result.Err != nil {
+			t.Fatalf("Test failed, %v", result.Err)
+		}
+		findings = append(findings, rules.Rule(result.Query).Finding(result.Match))
+	}
+	sort.Slice(findings, func(i, j int) bool {
+		if findings[i].Match.Filename != findings[j].Match.Filename {
+			return findings[i].Match.Filename < findings[j].Match.Filename
+		}
+		return findings[i].Match.Idx0 < findings[j].Match.Idx0
+	})
+
+	var buffer bytes.Buffer
+	if err := WriteSARIF(&buffer, rules, findings); err != nil {
+		t.Fatalf("Test failed, %v", err)
+	}
+
+	golden := "testdata/findings.sarif"
+	if *update {
+		if err := os.WriteFile(golden, buffer.Bytes(), 0644); err != nil {
+			t.Fatalf("Unable to update golden file, %v", err)
+		}
+	}
+
+	expected, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("Unable to read golden file, %v", err)
+	}
+	if !bytes.Equal(buffer.Bytes(), expected) {
+		t.Errorf("SARIF log not correct, was\n%v", buffer.String())
+	}
+
+	var log map[string]interface{}
+	if err := json.Unmarshal(buffer.Bytes(), &log); err != nil || log["version"] != "2.1.0" {
+		t.Errorf("SARIF log is not valid, %v", err)
+	}
+}
@@ -0,0 +1,200 @@
+package astquery
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/robertkrimen/otto/parser"
+)
+
+// ScanResult is a match of a named query in a scanned file, or the error of a path that could not be scanned
+type ScanResult struct {
+	// Query is the name of the matching query, empty for errors
+	Query string
+
+	// Match holds the match with its file, line and column, nil for errors
+	Match *Match
+
+	// Filename is the file matched or failing
+	Filename string
+
+	// Err is the reason the file could not be scanned, e.g. a syntax error
+	Err error
+}
+
+// namedQuery is a query added to a scanner
+type namedQuery struct {
+	name  string
+	query *Query
+}
+
+// Scanner runs named queries over JavaScript files, parsing the files in parallel
+type Scanner struct {
+	queries []namedQuery
+
+	// Workers is the number of files scanned at once, the number of CPUs if zero or less
+	Workers int
+
+	// Extensions are the extensions of the files scanned in directories, .js if empty.
+	// Files given directly or by glob are scanned whatever their extension.
+	Extensions []string
+}
+
+// NewScanner returns a scanner without queries
+func NewScanner() *Scanner {
+	return &Scanner{}
+}
+
+// Add adds a query to be run on every file, reporting its matches under the name.
+func (s *Scanner) Add(name string, q *Query) *Scanner {
+	s.queries = append(s.queries, namedQuery{
+		name:  name,
+		query: q,
+	})
+	return s
+}
+
+// Scan scans the files given by the paths, which are files, directories scanned recursively, or glob
+// patterns as understood by filepath.Match. Matches are sent on the returned channel as they are found,
+// which is closed when every file is scanned or the context is done. Files are scanned in no particular
+// order, but the matches of a file are sent together, query by query in the order the queries were added,
+// each in source order. Paths that cannot be read or parsed, and globs matching no file, are sent as results
+// with an error, the scan continues.
+func (s *Scanner) Scan(ctx context.Context, paths ...string) <-chan ScanResult {
+	workers := s.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	results := make(chan ScanResult)
+	files := make(chan string)
+	send := func(result ScanResult) bool {
+		select {
+		case results <- result:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	go func() {
+		defer close(files)
+		s.files(ctx, paths, func(filename string) bool {
+			select {
+			case files <- filename:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}, send)
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for filename := range files {
+				s.scanFile(ctx, filename, send)
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	return results
+}
+
+// files calls file with every file given by the paths until it returns false, and fail with the paths
+// that cannot be expanded, including globs matching nothing.
+func (s *Scanner) files(ctx context.Context, paths []string, file func(string) bool, fail func(ScanResult) bool) {
+	for _, path := range paths {
+		matches := []string{path}
+		if strings.ContainsAny(path, "*?[") {
+			var err error
+			matches, err = filepath.Glob(path)
+			if err == nil && len(matches) == 0 {
+				err = fmt.Errorf("No files match %v", path)
+			}
+			if err != nil {
+				if !fail(ScanResult{Filename: path, Err: err}) {
+					return
+				}
+				continue
+			}
+		}
+
+		for _, match := range matches {
+			err := filepath.WalkDir(match, func(filename string, entry fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				if entry.IsDir() || (filename != match && !s.scanned(filename)) {
+					return nil
+				}
+				if !file(filename) {
+					return ctx.Err()
+				}
+
+				return nil
+			})
+			if err != nil && ctx.Err() == nil {
+				if !fail(ScanResult{Filename: match, Err: err}) {
+					return
+				}
+			}
+			if ctx.Err() != nil {
+				return
+			}
+		}
+	}
+}
+
+// scanned determines if a file found in a directory is scanned given its extension.
+func (s *Scanner) scanned(filename string) bool {
+	extensions := s.Extensions
+	if len(extensions) == 0 {
+		extensions = []string{".js"}
+	}
+
+	for _, extension := range extensions {
+		if strings.HasSuffix(filename, extension) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// scanFile runs every query on the file, sending the matches.
+func (s *Scanner) scanFile(ctx context.Context, filename string, send func(ScanResult) bool) {
+	program, err := parser.ParseFile(nil, filename, nil, 0)
+	if err != nil {
+		send(ScanResult{Filename: filename, Err: err})
+		return
+	}
+
+	for _, q := range s.queries {
+		matches, err := q.query.FindAllContext(ctx, program, FindOptions{})
+		if err != nil {
+			return
+		}
+
+		for _, m := range matches {
+			if !send(ScanResult{Query: q.name, Match: m, Filename: filename}) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package astquery
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+// scanAll collects the results of a scan as sorted strings.
+func scanAll(s *Scanner, paths ...string) []string {
+	var results []string
+	for result := range s.Scan(context.Background(), paths...) {
+		if result.Err != nil {
+			results = append(results, fmt.Sprintf("%v: error", filepath.ToSlash(result.Filename)))
+			continue
+		}
+
+		m := result.Match
+		results = append(results, fmt.Sprintf("%v:%v:%v: %v", filepath.ToSlash(m.Filename), m.Line, m.Column, result.Query))
+	}
+	sort.Strings(results)
+
+	return results
+}
+
+func TestScanner_Scan(t *testing.T) {
+	scanner := NewScanner().
+		Add("console", NewQuery().MustBeCall().CalleePath("console.*")).
+		Add("var", NewQuery().MustBeVariable())
+
+	tests := []struct {
+		paths    []string
+		expected []string
+	}{
+		// Test 0, directories are scanned recursively for .js files
+		{[]string{"testdata/scan"}, []string{
+			"testdata/scan/a.js:1:1: console",
+			"testdata/scan/broken.js: error",
+			"testdata/scan/lib/b.js:1:5: var",
+			"testdata/scan/lib/b.js:3:1: console",
+		}},
+
+		// Test 1, globs
+		{[]string{"testdata/scan/*/*.js", "testdata/scan/a.*"}, []string{
+			"testdata/scan/a.js:1:1: console",
+			"testdata/scan/lib/b.js:1:5: var",
+			"testdata/scan/lib/b.js:3:1: console",
+		}},
+
+		// Test 2, files are scanned whatever their extension
+		{[]string{"testdata/scan/notes.txt"}, []string{
+			"testdata/scan/notes.txt:1:1: console",
+		}},
+
+		// Test 3, missing paths are reported
+		{[]string{"testdata/scan/missing.js", "testdata/scan/a.js"}, []string{
+			"testdata/scan/a.js:1:1: console",
+			"testdata/scan/missing.js: error",
+		}},
+
+		// Test 4, globs matching nothing are reported
+		{[]string{"testdata/scan/*.jss", "testdata/scan/a.js"}, []string{
+			"testdata/scan/*.jss: error",
+			"testdata/scan/a.js:1:1: console",
+		}},
+	}
+
+	for i, test := range tests {
+		results := scanAll(scanner, test.paths...)
+		if fmt.Sprint(results) != fmt.Sprint(test.expected) {
+			t.Errorf("Test %v failed, expected %v, got %v", i, test.expected, results)
+		}
+	}
+}
+
+func TestScanner_Scan_workers(t *testing.T) {
+	scanner := NewScanner().Add("call", NewQuery().MustBeCall())
+	scanner.Workers = 1
+	scanner.Extensions = []string{".js", ".txt"}
+
+	results := scanAll(scanner, "testdata/scan")
+	expected := []string{
+		"testdata/scan/a.js:1:1: call",
+		"testdata/scan/a.js:2:1: call",
+		"testdata/scan/broken.js: error",
+		"testdata/scan/lib/b.js:3:1: call",
+		"testdata/scan/notes.txt:1:1: call",
+	}
+	if fmt.Sprint(results) != fmt.Sprint(expected) {
+		t.Errorf("Expected %v, got %v", expected, results)
+	}
+}
+
+func TestScanner_Scan_cancel(t *testing.T) {
+	scanner := NewScanner().Add("call", NewQuery().MustBeCall())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	results := scanner.Scan(ctx, "testdata/scan")
+	<-results
+	cancel()
+
+	// The channel is closed after cancelling, without reading every result
+	for range results {
+	}
+}
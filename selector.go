@@ -0,0 +1,448 @@
+package astquery
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/robertkrimen/otto/ast"
+)
+
+// Compile compiles a textual selector into a query.
+//
+// A selector is a list of compound selectors separated by combinators.
+// A compound selector is a node kind, e.g. BinaryExpression, or * for any kind,
+// followed by any number of attribute filters:
+//
+//	[name]          the node has the attribute
+//	[name=value]    the attribute equals value
+//	[name!=value]   the attribute does not equal value
+//
+// Values may be quoted with single or double quotes. The supported attributes are
+// operator, name, value, pattern and flags. Values are compared as strings, except
+// the value of a number literal, which is compared numerically, e.g. [value=1.0] matches 1.
+//
+// A compound selector may also be followed by :not(selectors), which requires that
+// none of the selectors match the node.
+//
+// The combinator > requires the right side to be a direct child of the left side,
+// whitespace requires the right side to be a descendant. Several selectors can be
+// given separated by commas, in which case any of them must match.
+//
+//	BinaryExpression[operator="*"] > NumberLiteral
+//
+// As in CSS, the compiled query matches the node of the rightmost compound selector,
+// e.g. every number multiplied above. The compound selectors to its left are matched
+// against its ancestors, which are only known when the query is run on a program,
+// e.g. by FindAll.
+func Compile(selector string) (*Query, error) {
+	p := &selectorParser{src: selector}
+	q, err := p.parseSelectors(false)
+	if err != nil {
+		return nil, err
+	}
+
+	return q, nil
+}
+
+// MustCompile is like Compile, but panics if the selector cannot be parsed.
+func MustCompile(selector string) *Query {
+	q, err := Compile(selector)
+	if err != nil {
+		panic(err)
+	}
+
+	return q
+}
+
+// SyntaxError describes a selector that could not be parsed
+type SyntaxError struct {
+	// Column is the position in the selector where the error occurred, starting at 1
+	Column  int
+	Message string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("Syntax error at column %v: %v", e.Column, e.Message)
+}
+
+type selectorParser struct {
+	src string
+	pos int
+}
+
+func (p *selectorParser) errorf(format string, args ...interface{}) error {
+	return &SyntaxError{
+		Column:  p.pos + 1,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+func (p *selectorParser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *selectorParser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+
+	return p.src[p.pos]
+}
+
+// skipSpace skips whitespace and reports whether any was skipped.
+func (p *selectorParser) skipSpace() bool {
+	start := p.pos
+	for !p.eof() && isSpace(p.peek()) {
+		p.pos++
+	}
+
+	return p.pos > start
+}
+
+// parseSelectors parses selectors separated by commas. Nested selectors end at ).
+func (p *selectorParser) parseSelectors(nested bool) (*Query, error) {
+	var queries []*Query
+	for {
+		p.skipSpace()
+		q, err := p.parseSelector()
+		if err != nil {
+			return nil, err
+		}
+		queries = append(queries, q)
+
+		if p.eof() || (nested && p.peek() == ')') {
+			break
+		}
+		if p.peek() != ',' {
+			return nil, p.errorf("unexpected %q", p.peek())
+		}
+		p.pos++
+	}
+
+	if len(queries) == 1 {
+		return queries[0], nil
+	}
+
+	return NewQuery().Either(queries...), nil
+}
+
+// parseSelector parses compound selectors separated by combinators.
+func (p *selectorParser) parseSelector() (*Query, error) {
+	var compounds []*Query
+	var combinators []byte
+
+	for {
+		q, err := p.parseCompound()
+		if err != nil {
+			return nil, err
+		}
+		compounds = append(compounds, q)
+
+		spaced := p.skipSpace()
+		if p.eof() || p.peek() == ',' || p.peek() == ')' {
+			break
+		}
+
+		combinator := byte(' ')
+		if p.peek() == '>' {
+			combinator = '>'
+			p.pos++
+			p.skipSpace()
+		} else if !spaced {
+			return nil, p.errorf("unexpected %q", p.peek())
+		}
+		combinators = append(combinators, combinator)
+	}
+
+	// The rightmost compound is the subject, the compounds to its left must match its ancestors
+	q := compounds[0]
+	for i, combinator := range combinators {
+		subject := compounds[i+1]
+		if combinator == '>' {
+			subject.add(">", &axisQuery{axis: axisParent, query: q})
+		} else {
+			subject.add(" ", &axisQuery{axis: axisAncestor, query: q})
+		}
+		q = subject
+	}
+
+	return q, nil
+}
+
+// parseCompound parses a kind or * followed by attributes.
+func (p *selectorParser) parseCompound() (*Query, error) {
+	q := NewQuery()
+
+	switch c := p.peek(); {
+	case c == '*':
+		p.pos++
+	case isNameStart(c):
+		start := p.pos
+		kind := p.parseName()
+		if !nodeKinds[kind] {
+			p.pos = start
+			return nil, p.errorf("unknown node kind %q", kind)
+		}
+		q.is(kind, kind)
+	case c == '[' || c == ':':
+	case p.eof():
+		return nil, p.errorf("expected selector")
+	default:
+		return nil, p.errorf("unexpected %q", c)
+	}
+
+	for {
+		start := p.pos
+		switch p.peek() {
+		case '[':
+			op, err := p.parseAttribute()
+			if err != nil {
+				return nil, err
+			}
+			q.add(p.src[start:p.pos], op)
+		case ':':
+			op, err := p.parsePseudo()
+			if err != nil {
+				return nil, err
+			}
+			q.add(p.src[start:p.pos], op)
+		default:
+			return q, nil
+		}
+	}
+}
+
+// parsePseudo parses :not(selectors).
+func (p *selectorParser) parsePseudo() (QLOperation, error) {
+	p.pos++ // :
+	start := p.pos
+	name := p.parseName()
+	if name != "not" {
+		p.pos = start
+		return nil, p.errorf("unknown pseudo class %q", name)
+	}
+
+	if p.peek() != '(' {
+		return nil, p.errorf("expected (")
+	}
+	p.pos++
+
+	q, err := p.parseSelectors(true)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.peek() != ')' {
+		return nil, p.errorf("expected )")
+	}
+	p.pos++
+
+	return &not{query: q}, nil
+}
+
+func (p *selectorParser) parseName() string {
+	start := p.pos
+	for !p.eof() && isNamePart(p.peek()) {
+		p.pos++
+	}
+
+	return p.src[start:p.pos]
+}
+
+// parseAttribute parses [name], [name=value] and [name!=value].
+func (p *selectorParser) parseAttribute() (*attributeQuery, error) {
+	p.pos++ // [
+	p.skipSpace()
+
+	if !isNameStart(p.peek()) {
+		return nil, p.errorf("expected attribute name")
+	}
+	start := p.pos
+	name := p.parseName()
+	if !attributes[name] {
+		p.pos = start
+		return nil, p.errorf("unknown attribute %q", name)
+	}
+	op := &attributeQuery{name: name}
+
+	p.skipSpace()
+	switch {
+	case p.peek() == ']':
+		p.pos++
+		return op, nil
+	case p.peek() == '=':
+		p.pos++
+	case strings.HasPrefix(p.src[p.pos:], "!="):
+		op.negate = true
+		p.pos += 2
+	default:
+		return nil, p.errorf("expected =, != or ]")
+	}
+
+	p.skipSpace()
+	value, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	op.value = &value
+
+	p.skipSpace()
+	if p.peek() != ']' {
+		return nil, p.errorf("expected ]")
+	}
+	p.pos++
+
+	return op, nil
+}
+
+// parseValue parses a quoted string or a bare value running until whitespace or ].
+func (p *selectorParser) parseValue() (string, error) {
+	quote := p.peek()
+	if quote != '"' && quote != '\'' {
+		start := p.pos
+		for !p.eof() && p.peek() != ']' && !isSpace(p.peek()) {
+			p.pos++
+		}
+		if p.pos == start {
+			return "", p.errorf("expected value")
+		}
+
+		return p.src[start:p.pos], nil
+	}
+
+	start := p.pos
+	p.pos++
+	var value []byte
+	for {
+		if p.eof() {
+			p.pos = start
+			return "", p.errorf("unterminated string")
+		}
+
+		c := p.peek()
+		p.pos++
+		switch c {
+		case quote:
+			return string(value), nil
+		case '\\':
+			if p.eof() {
+				p.pos = start
+				return "", p.errorf("unterminated string")
+			}
+			c = p.peek()
+			p.pos++
+		}
+		value = append(value, c)
+	}
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isNamePart(c byte) bool {
+	return isNameStart(c) || (c >= '0' && c <= '9')
+}
+
+// attributes holds the attribute names known to selectors
+var attributes = map[string]bool{
+	"operator": true,
+	"name":     true,
+	"value":    true,
+	"pattern":  true,
+	"flags":    true,
+}
+
+// attribute returns the textual value of a named attribute of an expression.
+func attribute(e ast.Node, name string) (string, bool) {
+	switch name {
+	case "operator":
+		switch t := e.(type) {
+		case *ast.AssignExpression:
+			return t.Operator.String(), true
+		case *ast.BinaryExpression:
+			return t.Operator.String(), true
+		case *ast.UnaryExpression:
+			return t.Operator.String(), true
+		}
+
+	case "name":
+		switch t := e.(type) {
+		case *ast.Identifier:
+			return t.Name, true
+		case *ast.VariableExpression:
+			return t.Name, true
+		case *ast.FunctionLiteral:
+			if t.Name != nil {
+				return t.Name.Name, true
+			}
+		}
+
+	case "value":
+		switch t := e.(type) {
+		case *ast.BooleanLiteral:
+			return strconv.FormatBool(t.Value), true
+		case *ast.NullLiteral:
+			return "null", true
+		case *ast.NumberLiteral:
+			return fmt.Sprint(t.Value), true
+		case *ast.RegExpLiteral:
+			return t.Literal, true
+		case *ast.StringLiteral:
+			return t.Value, true
+		}
+
+	case "pattern":
+		if t, isRegExp := e.(*ast.RegExpLiteral); isRegExp {
+			return t.Pattern, true
+		}
+
+	case "flags":
+		if t, isRegExp := e.(*ast.RegExpLiteral); isRegExp {
+			return t.Flags, true
+		}
+	}
+
+	return "", false
+}
+
+// attributeQuery filters expressions on the value of an attribute
+type attributeQuery struct {
+	name   string
+	value  *string
+	negate bool
+}
+
+func (qo *attributeQuery) run(s *state, e ast.Node) (ast.Node, Captures, error) {
+	actual, ok := attribute(e, qo.name)
+	if !ok {
+		return nil, nil, mismatch(e, "", "Expression does not have attribute %v, was %T", qo.name, e)
+	}
+
+	if qo.value != nil && attributeEquals(e, actual, *qo.value) == qo.negate {
+		return nil, nil, mismatch(e, "", "Invalid %v for expression, %v", qo.name, actual)
+	}
+
+	return e, nil, nil
+}
+
+// attributeEquals compares the value of an attribute of an expression, numerically for the value of
+// a number literal and exactly otherwise.
+func attributeEquals(e ast.Node, actual, expected string) bool {
+	if actual == expected {
+		return true
+	}
+
+	if _, isNumber := e.(*ast.NumberLiteral); !isNumber {
+		return false
+	}
+	af, err1 := strconv.ParseFloat(actual, 64)
+	ef, err2 := strconv.ParseFloat(expected, 64)
+
+	return err1 == nil && err2 == nil && af == ef
+}
@@ -0,0 +1,145 @@
+package astquery
+
+import (
+	"testing"
+
+	"github.com/robertkrimen/otto/ast"
+)
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		selector string
+		src      string
+		matches  int
+	}{
+		// Test 0
+		{`CallExpression`, `f(); a = 1; g();`, 2},
+
+		// Test 1
+		{`BinaryExpression[operator="*"] > NumberLiteral`, `a * 2; b * c; 3 + 4;`, 1},
+
+		// Test 2
+		{`BinaryExpression[operator=*]`, `a * 2; b + c;`, 1},
+
+		// Test 3
+		{`AssignExpression CallExpression[name]`, `a = b + f(); c = d;`, 0},
+
+		// Test 4
+		{`AssignExpression CallExpression > Identifier[name='f']`, `a = b + f(); c = g();`, 1},
+
+		// Test 5
+		{`VariableExpression[name!=_]`, `var _ = 1, a = 2, b;`, 2},
+
+		// Test 6
+		{`UnaryExpression, CallExpression`, `!a; f(); a + b;`, 2},
+
+		// Test 7
+		{`* > StringLiteral[value="eval"]`, `f("eval"); g("x");`, 1},
+
+		// Test 8
+		{`BinaryExpression > NumberLiteral[value=1.0]`, `a + 1; a + 2;`, 1},
+
+		// Test 9
+		{`[operator="!"]`, `!a; -a;`, 1},
+
+		// Test 10
+		{`StringLiteral:not(BinaryExpression > StringLiteral)`, `a + "b"; f("c");`, 1},
+
+		// Test 11
+		{`Identifier:not(CallExpression > Identifier[name=f], CallExpression > Identifier[name=g])`, `f(); g(); h(a);`, 2},
+
+		// Test 12, every matching child
+		{`ArrayLiteral > NumberLiteral`, `a = [1, 2, 3];`, 3},
+
+		// Test 13, every matching descendant
+		{`CallExpression Identifier`, `f(a, b, c);`, 4},
+
+		// Test 14, nodes with several matching ancestors match once
+		{`CallExpression Identifier[name=eval]`, `f(g(eval));`, 1},
+
+		// Test 15
+		{`CallExpression CallExpression Identifier`, `f(g(h(x)));`, 3},
+
+		// Test 16
+		{`FunctionLiteral > BlockStatement IfStatement CallExpression > Identifier`, `function f() { if (a) { g(h); } } if (b) { i(); }`, 2},
+
+		// Test 17, strings are compared exactly
+		{`StringLiteral[value=1]`, `f('1.0', '1');`, 1},
+
+		// Test 18
+		{`StringLiteral[value="1"]`, `f('1.00');`, 0},
+
+		// Test 19
+		{`NumberLiteral[value="1"]`, `f(1.00, 0x1, 2);`, 2},
+	}
+
+	for i, test := range tests {
+		q, err := Compile(test.selector)
+		if err != nil {
+			t.Errorf("Test %v failed to compile, %v", i, err)
+			continue
+		}
+
+		matches := q.FindAll(parseProgram(t, test.src))
+		if len(matches) != test.matches {
+			t.Errorf("Test %v should have %v matches, got %v", i, test.matches, len(matches))
+		}
+	}
+}
+
+func TestCompile_subject(t *testing.T) {
+	q := MustCompile(`BinaryExpression > CallExpression > Identifier`)
+
+	matches, err := QuerySource(`a + f();`, q)
+	if err != nil {
+		t.Fatalf("Test failed, %v", err)
+	}
+	if len(matches) != 1 {
+		t.Fatalf("Expected 1 match, got %v", len(matches))
+	}
+
+	m := matches[0]
+	if id, isIdentifier := m.Node.(*ast.Identifier); !isIdentifier || id.Name != "f" || m.Result != m.Node {
+		t.Errorf("Match should be identifier f, was %v", m.Node)
+	}
+	if m.Line != 1 || m.Column != 5 {
+		t.Errorf("Match should be at 1:5, was %v:%v", m.Line, m.Column)
+	}
+}
+
+func TestCompile_errors(t *testing.T) {
+	tests := []struct {
+		selector string
+		column   int
+	}{
+		{``, 1},
+		{`Foo`, 1},
+		{`CallExpression >`, 17},
+		{`CallExpression[`, 16},
+		{`CallExpression[foo=1]`, 16},
+		{`CallExpression[name=`, 21},
+		{`CallExpression[name="f]`, 21},
+		{`CallExpression[name="f"`, 24},
+		{`CallExpression,`, 16},
+		{`CallExpression)`, 15},
+		{`CallExpression:has(Identifier)`, 16},
+		{`CallExpression:not(Identifier`, 30},
+	}
+
+	for i, test := range tests {
+		_, err := Compile(test.selector)
+		if err == nil {
+			t.Errorf("Test %v should have failed", i)
+			continue
+		}
+
+		syntaxError, isSyntaxError := err.(*SyntaxError)
+		if !isSyntaxError {
+			t.Errorf("Test %v should fail with a syntax error, was %T", i, err)
+			continue
+		}
+		if syntaxError.Column != test.column {
+			t.Errorf("Test %v should fail at column %v, was %v: %v", i, test.column, syntaxError.Column, err)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package astquery
+
+import (
+	"context"
+
+	"github.com/robertkrimen/otto/parser"
+)
+
+// QuerySource parses JavaScript source and runs the query at every node of the program,
+// like FindAll.
+func QuerySource(src string, q *Query) ([]*Match, error) {
+	return queryFile("", src, q)
+}
+
+// QueryFile parses the JavaScript file at path and runs the query at every node of the program,
+// like FindAll. The matches are annotated with the path.
+func QueryFile(path string, q *Query) ([]*Match, error) {
+	return queryFile(path, nil, q)
+}
+
+// queryFile parses src, or the file if src is nil, and finds all matches of the query in the program.
+func queryFile(filename string, src interface{}, q *Query) ([]*Match, error) {
+	program, err := parser.ParseFile(nil, filename, src, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return q.FindAllContext(context.Background(), program, FindOptions{})
+}
@@ -0,0 +1,85 @@
+package astquery
+
+import (
+	"testing"
+)
+
+func TestQuerySource(t *testing.T) {
+	matches, err := QuerySource("a = 1;\n\n  f(a);\nvar b = 2, c = g();", NewQuery().MustBeCall())
+	if err != nil {
+		t.Fatalf("Test failed, %v", err)
+	}
+
+	positions := [][2]int{{3, 3}, {4, 16}}
+	if len(matches) != len(positions) {
+		t.Fatalf("Expected %v matches, got %v", len(positions), len(matches))
+	}
+
+	for i, position := range positions {
+		if matches[i].Line != position[0] || matches[i].Column != position[1] {
+			t.Errorf("Match %v should be at %v:%v, was %v:%v", i, position[0], position[1], matches[i].Line, matches[i].Column)
+		}
+	}
+}
+
+func TestQuerySource_every_node(t *testing.T) {
+	tests := []struct {
+		src     string
+		query   *Query
+		matches int
+	}{
+		// Test 0
+		{`a = f(g(1)); h(i());`, NewQuery().MustBeCall(), 4},
+
+		// Test 1
+		{`f("a"); var x = "b";`, MustCompile("StringLiteral"), 2},
+
+		// Test 2, empty programs and sequences
+		{``, NewQuery().MustBeCall(), 0},
+
+		// Test 3
+		{`for (;;) {}`, NewQuery().MustBeCall(), 0},
+	}
+
+	for i, test := range tests {
+		matches, err := QuerySource(test.src, test.query)
+		if err != nil {
+			t.Errorf("Test %v failed, %v", i, err)
+			continue
+		}
+
+		if len(matches) != test.matches {
+			t.Errorf("Test %v should have %v matches, got %v", i, test.matches, len(matches))
+		}
+	}
+}
+
+func TestQuerySource_syntax_error(t *testing.T) {
+	_, err := QuerySource("a = ;", NewQuery().MustBeCall())
+	if err == nil {
+		t.Errorf("Test should have failed")
+	}
+}
+
+func TestQueryFile(t *testing.T) {
+	matches, err := QueryFile("testdata/source.js", NewQuery().MustBeCall())
+	if err != nil {
+		t.Fatalf("Test failed, %v", err)
+	}
+
+	if len(matches) != 2 {
+		t.Fatalf("Expected 2 matches, got %v", len(matches))
+	}
+
+	m := matches[0]
+	if m.Filename != "testdata/source.js" || m.Line != 3 || m.Column != 9 {
+		t.Errorf("Match should be at testdata/source.js:3:9, was %v:%v:%v", m.Filename, m.Line, m.Column)
+	}
+}
+
+func TestQueryFile_missing(t *testing.T) {
+	_, err := QueryFile("testdata/missing.js", NewQuery().MustBeCall())
+	if err == nil {
+		t.Errorf("Test should have failed")
+	}
+}
@@ -0,0 +1,306 @@
+package astquery
+
+import (
+	"github.com/robertkrimen/otto/ast"
+)
+
+// nodePart returns a named part of a node, e.g. the test of an if statement, or nil if the node does not have the part.
+func nodePart(node ast.Node, part string) ast.Node {
+	var expression ast.Expression
+	var statement ast.Statement
+
+	switch t := node.(type) {
+	case *ast.IfStatement:
+		switch part {
+		case "test":
+			expression = t.Test
+		case "consequent":
+			statement = t.Consequent
+		case "alternate":
+			statement = t.Alternate
+		}
+	case *ast.ConditionalExpression:
+		switch part {
+		case "test":
+			expression = t.Test
+		case "consequent":
+			expression = t.Consequent
+		case "alternate":
+			expression = t.Alternate
+		}
+	case *ast.ForStatement:
+		switch part {
+		case "init":
+			expression = t.Initializer
+		case "test":
+			expression = t.Test
+		case "update":
+			expression = t.Update
+		case "body":
+			statement = t.Body
+		}
+	case *ast.ForInStatement:
+		switch part {
+		case "init":
+			expression = t.Into
+		case "source":
+			expression = t.Source
+		case "body":
+			statement = t.Body
+		}
+	case *ast.WhileStatement:
+		switch part {
+		case "test":
+			expression = t.Test
+		case "body":
+			statement = t.Body
+		}
+	case *ast.DoWhileStatement:
+		switch part {
+		case "test":
+			expression = t.Test
+		case "body":
+			statement = t.Body
+		}
+	case *ast.SwitchStatement:
+		if part == "discriminant" {
+			expression = t.Discriminant
+		}
+	case *ast.CaseStatement:
+		if part == "test" {
+			expression = t.Test
+		}
+	case *ast.TryStatement:
+		switch part {
+		case "body":
+			statement = t.Body
+		case "catch":
+			if t.Catch != nil {
+				statement = t.Catch
+			}
+		case "finally":
+			statement = t.Finally
+		}
+	case *ast.CatchStatement:
+		switch part {
+		case "parameter":
+			if t.Parameter != nil {
+				expression = t.Parameter
+			}
+		case "body":
+			statement = t.Body
+		}
+	case *ast.ReturnStatement:
+		if part == "argument" {
+			expression = t.Argument
+		}
+	case *ast.ThrowStatement:
+		if part == "argument" {
+			expression = t.Argument
+		}
+	case *ast.LabelledStatement:
+		switch part {
+		case "label":
+			if t.Label != nil {
+				expression = t.Label
+			}
+		case "body":
+			statement = t.Statement
+		}
+	case *ast.BranchStatement:
+		if part == "label" && t.Label != nil {
+			expression = t.Label
+		}
+	case *ast.WithStatement:
+		if part == "body" {
+			statement = t.Body
+		}
+	case *ast.FunctionLiteral:
+		if part == "body" {
+			statement = t.Body
+		}
+	}
+
+	if expression != nil {
+		return expression
+	}
+	if statement != nil {
+		return statement
+	}
+
+	return nil
+}
+
+// partQuery runs a query on a named part of the current node
+type partQuery struct {
+	part  string
+	query *Query
+}
+
+func (qo *partQuery) run(s *state, e ast.Node) (ast.Node, Captures, error) {
+	part := nodePart(e, qo.part)
+	if part == nil {
+		return nil, nil, mismatch(e, "", "Node does not have a %v, was %T", qo.part, e)
+	}
+
+	result, err := s.sub(qo.query, part)
+	if err != nil {
+		return nil, nil, mismatch(e, "", "The %v did not match", qo.part).because(err)
+	}
+
+	return e, result.Captures, nil
+}
+
+func (q *Query) part(name, part string, query *Query) *Query {
+	q.add(name, &partQuery{
+		part:  part,
+		query: query,
+	})
+	return q
+}
+
+// Test runs the query on the test of an if, for, while, do while, case or conditional.
+func (q *Query) Test(query *Query) *Query {
+	return q.part("Test", "test", query)
+}
+
+// Consequent runs the query on the consequent of an if statement or a conditional expression.
+func (q *Query) Consequent(query *Query) *Query {
+	return q.part("Consequent", "consequent", query)
+}
+
+// Alternate runs the query on the alternate, the else part, of an if statement or a conditional expression.
+func (q *Query) Alternate(query *Query) *Query {
+	return q.part("Alternate", "alternate", query)
+}
+
+// Init runs the query on the initializer of a for statement, or the target of a for in statement.
+func (q *Query) Init(query *Query) *Query {
+	return q.part("Init", "init", query)
+}
+
+// Source runs the query on the object iterated by a for in statement.
+func (q *Query) Source(query *Query) *Query {
+	return q.part("Source", "source", query)
+}
+
+// Update runs the query on the update expression of a for statement.
+func (q *Query) Update(query *Query) *Query {
+	return q.part("Update", "update", query)
+}
+
+// Body runs the query on the body of a loop, try, catch, with, labelled statement or function literal.
+func (q *Query) Body(query *Query) *Query {
+	return q.part("Body", "body", query)
+}
+
+// Discriminant runs the query on the discriminant of a switch statement.
+func (q *Query) Discriminant(query *Query) *Query {
+	return q.part("Discriminant", "discriminant", query)
+}
+
+// Catch runs the query on the catch clause of a try statement.
+func (q *Query) Catch(query *Query) *Query {
+	return q.part("Catch", "catch", query)
+}
+
+// Finally runs the query on the finally block of a try statement.
+func (q *Query) Finally(query *Query) *Query {
+	return q.part("Finally", "finally", query)
+}
+
+// CatchParameter runs the query on the parameter of a catch clause.
+func (q *Query) CatchParameter(query *Query) *Query {
+	return q.part("CatchParameter", "parameter", query)
+}
+
+// Argument runs the query on the argument of a return or throw statement.
+func (q *Query) Argument(query *Query) *Query {
+	return q.part("Argument", "argument", query)
+}
+
+// Label runs the query on the label of a labelled, break or continue statement.
+func (q *Query) Label(query *Query) *Query {
+	return q.part("Label", "label", query)
+}
+
+// MustBeIf restricts the node to be an if statement
+func (q *Query) MustBeIf() *Query {
+	return q.is("MustBeIf", "IfStatement")
+}
+
+// MustBeFor restricts the node to be a for statement
+func (q *Query) MustBeFor() *Query {
+	return q.is("MustBeFor", "ForStatement")
+}
+
+// MustBeForIn restricts the node to be a for in statement
+func (q *Query) MustBeForIn() *Query {
+	return q.is("MustBeForIn", "ForInStatement")
+}
+
+// MustBeWhile restricts the node to be a while statement
+func (q *Query) MustBeWhile() *Query {
+	return q.is("MustBeWhile", "WhileStatement")
+}
+
+// MustBeDoWhile restricts the node to be a do while statement
+func (q *Query) MustBeDoWhile() *Query {
+	return q.is("MustBeDoWhile", "DoWhileStatement")
+}
+
+// MustBeSwitch restricts the node to be a switch statement, the cases are its elements, see AllOf
+func (q *Query) MustBeSwitch() *Query {
+	return q.is("MustBeSwitch", "SwitchStatement")
+}
+
+// MustBeCase restricts the node to be a case of a switch statement, including the default case
+func (q *Query) MustBeCase() *Query {
+	return q.is("MustBeCase", "CaseStatement")
+}
+
+// MustBeTry restricts the node to be a try statement
+func (q *Query) MustBeTry() *Query {
+	return q.is("MustBeTry", "TryStatement")
+}
+
+// MustBeCatchClause restricts the node to be the catch clause of a try statement
+func (q *Query) MustBeCatchClause() *Query {
+	return q.is("MustBeCatchClause", "CatchStatement")
+}
+
+// MustBeReturn restricts the node to be a return statement
+func (q *Query) MustBeReturn() *Query {
+	return q.is("MustBeReturn", "ReturnStatement")
+}
+
+// MustBeThrow restricts the node to be a throw statement
+func (q *Query) MustBeThrow() *Query {
+	return q.is("MustBeThrow", "ThrowStatement")
+}
+
+// MustBeLabelled restricts the node to be a labelled statement
+func (q *Query) MustBeLabelled() *Query {
+	return q.is("MustBeLabelled", "LabelledStatement")
+}
+
+// MustBeBlock restricts the node to be a block statement, the statements are its elements, see AllOf
+func (q *Query) MustBeBlock() *Query {
+	return q.is("MustBeBlock", "BlockStatement")
+}
+
+// MustBeEmptyBlock restricts the node to be a block statement without statements
+func (q *Query) MustBeEmptyBlock() *Query {
+	return q.predicate("MustBeEmptyBlock", func(e ast.Node) error {
+		block, isBlock := e.(*ast.BlockStatement)
+		if !isBlock {
+			return mismatch(e, "BlockStatement", "Node is not a block statement, was %T", e)
+		}
+
+		if len(block.List) > 0 {
+			return mismatch(e, "", "Block statement has %v statements", len(block.List))
+		}
+
+		return nil
+	})
+}
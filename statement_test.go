@@ -0,0 +1,74 @@
+package astquery
+
+import (
+	"testing"
+
+	"github.com/robertkrimen/otto/ast"
+)
+
+func TestStatementQueries(t *testing.T) {
+	tests := []struct {
+		src     string
+		query   *Query
+		matches int
+	}{
+		// Test 0, empty catch block
+		{`try { f(); } catch (e) {} try { g(); } catch (e) { h(e); }`, NewQuery().MustBeTry().Catch(NewQuery().Body(NewQuery().MustBeEmptyBlock())), 1},
+
+		// Test 1, assignment inside if test
+		{`if (a = b) {} if (a == b) {} if (c && (a = b)) {}`, NewQuery().MustBeIf().Test(NewQuery().Either(NewQuery().MustBeAssign(), NewQuery().Has(NewQuery().MustBeAssign()))), 2},
+
+		// Test 2
+		{`if (a) { f(); } else { g(); } if (a) { f(); }`, NewQuery().MustBeIf().Alternate(NewQuery().MustBeBlock()), 1},
+
+		// Test 3
+		{`for (i = 0; i < 1; i++) {} for (;;) {}`, NewQuery().MustBeFor().Init(NewQuery().AnyOf(NewQuery().MustBeAssign())).Test(NewQuery().MustBeBinary()).Update(NewQuery().MustBeUnary()), 1},
+
+		// Test 4
+		{`while (true) { f(); } do { g(); } while (true); while (a) {}`, NewQuery().Test(NewQuery().BooleanIs(true)).Body(NewQuery().MustBeBlock()), 2},
+
+		// Test 5, switch without default
+		{`switch (a) { case 1: f(); } switch (a) { case 1: f(); default: g(); }`, NewQuery().MustBeSwitch().Discriminant(NewQuery().MustBeIdentifier()).AllOf(NewQuery().MustBeCase().Test(NewQuery())), 1},
+
+		// Test 6
+		{`function f() { throw "a"; throw new Error("b"); }`, NewQuery().MustBeThrow().Argument(NewQuery().MustBeStringLiteral()), 1},
+
+		// Test 7
+		{`function f() { return; return a; }`, NewQuery().MustBeReturn().Argument(NewQuery().MustBeIdentifier()), 1},
+
+		// Test 8
+		{`outer: for (;;) { break outer; } inner: while (a) {}`, NewQuery().MustBeLabelled().Label(NewQuery().NameIs("outer")).Body(NewQuery().MustBeFor()), 1},
+
+		// Test 9
+		{`try {} finally { f(); } try {} catch (e) {}`, NewQuery().MustBeTry().Finally(NewQuery().Has(NewQuery().MustBeCall())), 1},
+
+		// Test 10
+		{`try {} catch (err) {} try {} catch (e) {}`, NewQuery().MustBeCatchClause().CatchParameter(NewQuery().NameIs("e")), 1},
+
+		// Test 11
+		{`a ? b : c; a ? 1 : c;`, NewQuery().MustBeConditional().Consequent(NewQuery().MustBeNumberLiteral()), 1},
+
+		// Test 12, expression queries relative to statements
+		{`if (a) { f(); } g();`, NewQuery().MustBeCall().Inside(NewQuery().MustBeIf()), 1},
+
+		// Test 13
+		{`for (k in obj) {} for (k in f()) {}`, NewQuery().MustBeForIn().Init(NewQuery().NameIs("k")).Source(NewQuery().NameIs("obj")), 1},
+	}
+
+	for i, test := range tests {
+		program := parseProgram(t, test.src)
+
+		count := 0
+		s := &state{parents: newParents(program)}
+		walk(program, func(node ast.Node, path []ast.Node) bool {
+			if _, err := test.query.eval(s, node); err == nil {
+				count++
+			}
+			return true
+		})
+
+		if count != test.matches {
+			t.Errorf("Test %v should have %v matches, got %v", i, test.matches, count)
+		}
+	}
+}
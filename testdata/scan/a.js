@@ -0,0 +1,2 @@
+console.log("a");
+f();
@@ -0,0 +1,3 @@
+var x = 1;
+
+console.warn(x);
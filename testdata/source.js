@@ -0,0 +1,7 @@
+function f(a) {
+    if (a) {
+        console.log(a);
+    }
+}
+
+f(1);
@@ -0,0 +1 @@
+var s = "héllo 😀"; console.info(s);
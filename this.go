@@ -1,33 +1,74 @@
 package astquery
 
 import (
-	"fmt"
 	"github.com/robertkrimen/otto/ast"
 )
 
-// booleanQuery will determine if a part of the tree is solely booleans
+// thisQuery will determine if a part of the tree contains this
 type thisQuery struct {
-	expression ast.Expression
+	scoped bool
 }
 
-func (qo *thisQuery) run(expression ast.Expression) error {
+func (qo *thisQuery) run(s *state, expression ast.Node) (ast.Node, Captures, error) {
 	inspector := &ThisInspector{}
-	Inspect(expression, inspector)
+	if qo.scoped {
+		InspectScope(expression, inspector)
+	} else {
+		Inspect(expression, inspector)
+	}
 
 	if inspector.Found == 0 {
-		return fmt.Errorf("Expression does not contain this")
+		return nil, nil, mismatch(expression, "", "Expression does not contain this")
 	}
 
-	return nil
+	return expression, nil, nil
 }
 
-func (qo *thisQuery) get() ast.Expression {
-	return qo.expression
+// ContainsThis will only pass if the subtree contains this, including this in nested functions.
+func (q *Query) ContainsThis() *Query {
+	q.add("ContainsThis", &thisQuery{
+	})
+	return q
 }
 
-// AcceptBoolean will only pass if the subtree is solely booleans.
-func (q *Query) ContainsThis() *Query {
-	q.operations = append(q.operations, &thisQuery{
+// ContainsScopedThis will only pass if the subtree contains this bound to the current function scope.
+// Nested functions are not searched, as they bind their own this. If the expression is a function
+// literal, its body is the current scope.
+func (q *Query) ContainsScopedThis() *Query {
+	q.add("ContainsScopedThis", &thisQuery{
+		scoped: true,
 	})
 	return q
 }
+
+// ThisUsage is a usage of this and the function it belongs to
+type ThisUsage struct {
+	This *ast.ThisExpression
+
+	// Function is the function binding this, or nil if this belongs to the global scope
+	Function *ast.FunctionLiteral
+}
+
+// FindThis returns all usages of this in the node, in source order, with the function they belong to.
+func FindThis(node ast.Node) []ThisUsage {
+	var usages []ThisUsage
+	walk(node, func(n ast.Node, path []ast.Node) bool {
+		this, isThis := n.(*ast.ThisExpression)
+		if !isThis {
+			return true
+		}
+
+		usage := ThisUsage{This: this}
+		for i := len(path) - 1; i >= 0; i-- {
+			if function, isFunction := path[i].(*ast.FunctionLiteral); isFunction {
+				usage.Function = function
+				break
+			}
+		}
+		usages = append(usages, usage)
+
+		return true
+	})
+
+	return usages
+}
@@ -81,3 +81,55 @@ func TestQuery_ContainsThis2(t *testing.T) {
 		t.Errorf("Test failed, %v", err)
 	}
 }
+
+func TestQuery_ContainsScopedThis(t *testing.T) {
+	program := parseProgram(t, `
+		a = this.b;
+		c = f(function() { return this; });
+		d = function() { return this; };
+	`)
+
+	matches := NewQuery().ContainsScopedThis().RunProgram(program)
+
+	// a = this.b, and the return statement inside each function
+	if len(matches) != 3 {
+		t.Errorf("Expected 3 matches, got %v", len(matches))
+	}
+
+	matches = NewQuery().MustBeAssign().RightSide(NewQuery().MustBeFunctionLiteral().ContainsScopedThis()).RunProgram(program)
+	if len(matches) != 1 {
+		t.Errorf("Expected 1 match, got %v", len(matches))
+	}
+
+	matches = NewQuery().MustBeAssign().RightSide(NewQuery().MustBeCall().ContainsScopedThis()).RunProgram(program)
+	if len(matches) != 0 {
+		t.Errorf("Expected no matches, got %v", len(matches))
+	}
+}
+
+func TestFindThis(t *testing.T) {
+	program := parseProgram(t, `
+		var a = this;
+		function f() {
+			var b = this;
+			return function g() { return this; };
+		}
+	`)
+
+	usages := FindThis(program)
+
+	functions := []string{"", "f", "g"}
+	if len(usages) != len(functions) {
+		t.Fatalf("Expected %v usages, got %v", len(functions), len(usages))
+	}
+
+	for i, name := range functions {
+		actual := ""
+		if usages[i].Function != nil {
+			actual = usages[i].Function.Name.Name
+		}
+		if actual != name {
+			t.Errorf("Usage %v should belong to %q, was %q", i, name, actual)
+		}
+	}
+}
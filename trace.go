@@ -0,0 +1,79 @@
+package astquery
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/robertkrimen/otto/ast"
+	"github.com/robertkrimen/otto/file"
+)
+
+// TraceEvent describes the evaluation of a single operation of a query
+type TraceEvent struct {
+	// Operation is the name of the query method adding the operation, e.g. "MustBeBinary"
+	Operation string
+
+	// Depth is the nesting of the query running the operation, zero for the traced query and one for its sub queries
+	Depth int
+
+	// Node is the node the operation was run on, Kind its kind and Idx its position
+	Node ast.Node
+	Kind string
+	Idx  file.Idx
+
+	// Output is the node the query continues from if the operation passed
+	Output ast.Node
+
+	// Err is the reason the operation failed, or nil if it passed
+	Err error
+}
+
+// Passed reports whether the operation passed.
+func (e *TraceEvent) Passed() bool {
+	return e.Err == nil
+}
+
+// Tracer receives an event for every operation evaluated. The operations of a
+// sub query are reported before the operation running the sub query.
+type Tracer func(event *TraceEvent)
+
+// TraceWriter returns a tracer writing a line per event to w, indented by depth.
+func TraceWriter(w io.Writer) Tracer {
+	return func(event *TraceEvent) {
+		indent := strings.Repeat("  ", event.Depth)
+		if event.Passed() {
+			fmt.Fprintf(w, "%v%v %v@%v pass -> %v\n", indent, event.Operation, event.Kind, event.Idx, kindOf(event.Output))
+		} else {
+			fmt.Fprintf(w, "%v%v %v@%v fail: %v\n", indent, event.Operation, event.Kind, event.Idx, event.Err)
+		}
+	}
+}
+
+// trace emits the event of an operation, if the run is traced.
+func (s *state) trace(operation string, node ast.Node, output ast.Node, err error) {
+	if s.tracer == nil {
+		return
+	}
+
+	idx, _ := nodeRange(node)
+	event := &TraceEvent{
+		Operation: operation,
+		Depth:     s.depth,
+		Node:      node,
+		Kind:      kindOf(node),
+		Idx:       idx,
+		Err:       err,
+	}
+	if err == nil {
+		event.Output = output
+	}
+
+	s.tracer(event)
+}
+
+// RunWithTracer runs the query like Run, reporting every operation evaluated,
+// including those of sub queries, to the tracer.
+func (ql *Query) RunWithTracer(node ast.Node, tracer Tracer) error {
+	return ql.keep(ql.eval(&state{tracer: tracer}, node))
+}
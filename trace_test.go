@@ -0,0 +1,74 @@
+package astquery
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/robertkrimen/otto/ast"
+	"github.com/robertkrimen/otto/token"
+)
+
+func TestQuery_RunWithTracer(t *testing.T) {
+	program := parseProgram(t, `a = 1 + f();`)
+	assign := program.Body[0].(*ast.ExpressionStatement).Expression
+
+	var events []*TraceEvent
+	q := NewQuery().MustBeAssign().RightSide(NewQuery().MustBeBinary().HasOperator(token.MINUS))
+	err := q.RunWithTracer(assign, func(event *TraceEvent) {
+		events = append(events, event)
+	})
+	if err == nil {
+		t.Fatalf("Expected the query to fail")
+	}
+
+	tests := []struct {
+		operation string
+		depth     int
+		kind      string
+		passed    bool
+	}{
+		{"MustBeAssign", 0, "AssignExpression", true},
+		{"MustBeBinary", 1, "BinaryExpression", true},
+		{"HasOperator", 1, "BinaryExpression", false},
+		{"RightSide", 0, "AssignExpression", false},
+	}
+
+	if len(events) != len(tests) {
+		t.Fatalf("Expected %v events, got %v", len(tests), len(events))
+	}
+	for i, test := range tests {
+		event := events[i]
+		if event.Operation != test.operation || event.Depth != test.depth || event.Kind != test.kind || event.Passed() != test.passed {
+			t.Errorf("Event %v not correct, was %+v", i, event)
+		}
+	}
+
+	if events[0].Output != assign || events[2].Output != nil {
+		t.Errorf("Event outputs not correct")
+	}
+	if events[1].Idx != assign.(*ast.AssignExpression).Right.Idx0() {
+		t.Errorf("Event position not correct, was %v", events[1].Idx)
+	}
+
+	// The tracer is removed after the run
+	events = nil
+	q.Run(assign)
+	if len(events) != 0 {
+		t.Errorf("Expected no events, got %v", len(events))
+	}
+}
+
+func TestTraceWriter(t *testing.T) {
+	program := parseProgram(t, `f();`)
+	call := program.Body[0].(*ast.ExpressionStatement).Expression
+
+	var buffer bytes.Buffer
+	NewQuery().MustBeCall().Not(NewQuery().MustBeCall()).RunWithTracer(call, TraceWriter(&buffer))
+
+	expected := "MustBeCall CallExpression@1 pass -> CallExpression\n" +
+		"  MustBeCall CallExpression@1 pass -> CallExpression\n" +
+		"Not CallExpression@1 fail: Expression matched negated query\n"
+	if buffer.String() != expected {
+		t.Errorf("Trace not correct, was\n%v", buffer.String())
+	}
+}
@@ -1,32 +1,24 @@
 package astquery
 
 import (
-	"fmt"
 	"github.com/robertkrimen/otto/ast"
 )
 
 // unaryQuery requires the current expression to be unary
 type unaryQuery struct {
-	unary *ast.UnaryExpression
 }
 
-func (qo *unaryQuery) run(e ast.Expression) error {
-	var isUnary bool
-	qo.unary, isUnary = e.(*ast.UnaryExpression)
-	if !isUnary {
-		return fmt.Errorf("Expression is not unary, was %T", e)
+func (qo *unaryQuery) run(s *state, e ast.Node) (ast.Node, Captures, error) {
+	if _, isUnary := e.(*ast.UnaryExpression); !isUnary {
+		return nil, nil, mismatch(e, "UnaryExpression", "Expression is not unary, was %T", e)
 	}
 
-	return nil
-}
-
-func (qo *unaryQuery) get() ast.Expression {
-	return qo.unary
+	return e, nil, nil
 }
 
 // MustBeUnary restricts the expression to be unary
 func (q *Query) MustBeUnary() *Query {
-	q.operations = append(q.operations, &unaryQuery{})
+	q.add("MustBeUnary", &unaryQuery{})
 	return q
 }
 
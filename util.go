@@ -88,9 +88,9 @@ func newOnlyThisVerifier() func(ast.Expression) bool {
 	}
 }
 
-// Inspector interface for inspecting a given expression and is used for the inspect function.
+// Inspector interface for inspecting a given node and is used for the inspect function.
 type Inspector interface {
-	Inspect(expression ast.Expression) Inspector
+	Inspect(node ast.Node) Inspector
 	Done() bool
 }
 
@@ -103,12 +103,12 @@ type CallInspector struct {
 	First bool
 }
 
-func (i *CallInspector) Inspect(expression ast.Expression) Inspector {
-	call, isCall := expression.(*ast.CallExpression)
+func (i *CallInspector) Inspect(node ast.Node) Inspector {
+	call, isCall := node.(*ast.CallExpression)
 	if isCall && ((i.Call == nil && !i.First) || (i.First)) {
 		i.Call = call
 	}
-	newe, isNew := expression.(*ast.NewExpression)
+	newe, isNew := node.(*ast.NewExpression)
 	if isNew && ((i.New == nil && !i.First) || (i.First)) {
 		i.New = newe
 	}
@@ -127,8 +127,8 @@ type ThisInspector struct {
 	Found int
 }
 
-func (i *ThisInspector) Inspect(expression ast.Expression) Inspector {
-	_, isThis := expression.(*ast.ThisExpression)
+func (i *ThisInspector) Inspect(node ast.Node) Inspector {
+	_, isThis := node.(*ast.ThisExpression)
 	if isThis {
 		i.Found++
 	}
@@ -140,105 +140,31 @@ func (i *ThisInspector) Done() bool {
 	return false
 }
 
-// Inspect will inspect a given expression, avoiding certain types of expressions.
-func Inspect(node ast.Expression, inspector Inspector) Inspector {
+// Inspect will inspect a given node and every node below it, including statements and function bodies.
+func Inspect(node ast.Node, inspector Inspector) Inspector {
+	return inspect(node, inspector, false)
+}
+
+// InspectScope will inspect a given node and every node below it, but will not enter nested functions.
+// Nested function literals are inspected themselves, but not their names, parameters and bodies.
+func InspectScope(node ast.Node, inspector Inspector) Inspector {
+	return inspect(node, inspector, true)
+}
 
+func inspect(node ast.Node, inspector Inspector, scoped bool) Inspector {
 	if inspector.Inspect(node).Done() {
 		return inspector
 	}
 
-	switch e := node.(type) {
-	case *ast.ArrayLiteral:
-		for _, v := range e.Value {
-			if Inspect(v, inspector).Done() {
-				return inspector
-			}
-		}
-	case *ast.AssignExpression:
-		if Inspect(e.Left, inspector).Done() {
-			return inspector
-		}
-		if Inspect(e.Right, inspector).Done() {
-			return inspector
-		}
-	case *ast.BinaryExpression:
-		if Inspect(e.Left, inspector).Done() {
-			return inspector
-		}
-		if Inspect(e.Right, inspector).Done() {
-			return inspector
-		}
-	case *ast.BracketExpression:
-		if Inspect(e.Left, inspector).Done() {
-			return inspector
-		}
-		if Inspect(e.Member, inspector).Done() {
-			return inspector
-		}
-	case *ast.CallExpression:
-		if Inspect(e.Callee, inspector).Done() {
-			return inspector
-		}
-		for _, l := range e.ArgumentList {
-			if Inspect(l, inspector).Done() {
+	for _, child := range children(node) {
+		if _, isFunction := child.(*ast.FunctionLiteral); isFunction && scoped {
+			if inspector.Inspect(child).Done() {
 				return inspector
 			}
+			continue
 		}
-	case *ast.ConditionalExpression:
-		if Inspect(e.Test, inspector).Done() {
-			return inspector
-		}
-		if Inspect(e.Consequent, inspector).Done() {
-			return inspector
-		}
-		if Inspect(e.Alternate, inspector).Done() {
-			return inspector
-		}
-	case *ast.DotExpression:
-		if Inspect(e.Left, inspector).Done() {
-			return inspector
-		}
-		if Inspect(e.Identifier, inspector).Done() {
-			return inspector
-		}
-	case *ast.FunctionLiteral:
-		if Inspect(e.Name, inspector).Done() {
-			return inspector
-		}
-		if e.ParameterList != nil {
-			for _, l := range e.ParameterList.List {
-				if Inspect(l, inspector).Done() {
-					return inspector
-				}
-			}
-		}
-	case *ast.NewExpression:
-		if Inspect(e.Callee, inspector).Done() {
-			return inspector
-		}
-		for _, l := range e.ArgumentList {
-			if Inspect(l, inspector).Done() {
-				return inspector
-			}
-		}
-	case *ast.ObjectLiteral:
-		for _, l := range e.Value {
-			if Inspect(l.Value, inspector).Done() {
-				return inspector
-			}
-		}
-	case *ast.SequenceExpression:
-		for _, l := range e.Sequence {
-			if Inspect(l, inspector).Done() {
-				return inspector
-			}
-		}
-	case *ast.UnaryExpression:
-		if Inspect(e.Operand, inspector).Done() {
-			return inspector
-		}
-	case *ast.VariableExpression:
-		if Inspect(e.Initializer, inspector).Done() {
+
+		if inspect(child, inspector, scoped).Done() {
 			return inspector
 		}
 	}
@@ -0,0 +1,52 @@
+package astquery
+
+import (
+	"testing"
+)
+
+func TestInspect_function_body(t *testing.T) {
+	program := parseProgram(t, `
+		function f() {
+			if (a) {
+				return this.b;
+			}
+		}
+		var g = function() {
+			try { h(this); } catch (e) {}
+		};
+	`)
+
+	inspector := &ThisInspector{}
+	Inspect(program, inspector)
+
+	if inspector.Found != 2 {
+		t.Errorf("Expected 2 this expressions, got %v", inspector.Found)
+	}
+}
+
+func TestInspectScope(t *testing.T) {
+	program := parseProgram(t, `
+		var a = this;
+		var g = function() {
+			return this;
+		};
+	`)
+
+	inspector := &ThisInspector{}
+	InspectScope(program, inspector)
+
+	if inspector.Found != 1 {
+		t.Errorf("Expected 1 this expression, got %v", inspector.Found)
+	}
+}
+
+func TestQuery_MustBeCallD_function_body(t *testing.T) {
+	program := parseProgram(t, `var a = function() { return f(); };`)
+
+	matches := NewQuery().MustBeCallD(false).RunProgram(program)
+
+	// Both the variable and the return statement of the function contain the call
+	if len(matches) != 2 {
+		t.Errorf("Expected 2 matches, got %v", len(matches))
+	}
+}
@@ -0,0 +1,218 @@
+package astquery
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/robertkrimen/otto/ast"
+)
+
+// predicateQuery filters expressions given a predicate returning why an expression was rejected
+type predicateQuery struct {
+	predicate func(ast.Node) error
+}
+
+func (qo *predicateQuery) run(s *state, e ast.Node) (ast.Node, Captures, error) {
+	if err := qo.predicate(e); err != nil {
+		return nil, nil, err
+	}
+
+	return e, nil, nil
+}
+
+func (q *Query) predicate(name string, predicate func(ast.Node) error) *Query {
+	q.add(name, &predicateQuery{
+		predicate: predicate,
+	})
+	return q
+}
+
+// name returns the name of identifiers, variables and named functions.
+func name(e ast.Node) (string, error) {
+	name, ok := attribute(e, "name")
+	if !ok {
+		return "", mismatch(e, "", "Expression does not have a name, was %T", e)
+	}
+
+	return name, nil
+}
+
+// NameIs will only pass if the identifier, variable or function has the given name.
+func (q *Query) NameIs(names ...string) *Query {
+	return q.predicate("NameIs", func(e ast.Node) error {
+		n, err := name(e)
+		if err != nil {
+			return err
+		}
+
+		for _, expected := range names {
+			if n == expected {
+				return nil
+			}
+		}
+
+		return mismatch(e, "", "Invalid name for expression, %v", n)
+	})
+}
+
+// NameMatches will only pass if the name of the identifier, variable or function matches the pattern.
+func (q *Query) NameMatches(pattern *regexp.Regexp) *Query {
+	return q.predicate("NameMatches", func(e ast.Node) error {
+		n, err := name(e)
+		if err != nil {
+			return err
+		}
+
+		if !pattern.MatchString(n) {
+			return mismatch(e, "", "Name %v does not match %v", n, pattern)
+		}
+
+		return nil
+	})
+}
+
+// stringValue returns the value of a string literal.
+func stringValue(e ast.Node) (string, error) {
+	literal, isString := e.(*ast.StringLiteral)
+	if !isString {
+		return "", mismatch(e, "StringLiteral", "Expression is not a string literal, was %T", e)
+	}
+
+	return literal.Value, nil
+}
+
+// StringValueIs will only pass if the string literal has one of the given values.
+func (q *Query) StringValueIs(values ...string) *Query {
+	return q.predicate("StringValueIs", func(e ast.Node) error {
+		s, err := stringValue(e)
+		if err != nil {
+			return err
+		}
+
+		for _, expected := range values {
+			if s == expected {
+				return nil
+			}
+		}
+
+		return mismatch(e, "", "Invalid value for string literal, %q", s)
+	})
+}
+
+// StringValueMatches will only pass if the value of the string literal matches the pattern.
+func (q *Query) StringValueMatches(pattern *regexp.Regexp) *Query {
+	return q.predicate("StringValueMatches", func(e ast.Node) error {
+		s, err := stringValue(e)
+		if err != nil {
+			return err
+		}
+
+		if !pattern.MatchString(s) {
+			return mismatch(e, "", "String value %q does not match %v", s, pattern)
+		}
+
+		return nil
+	})
+}
+
+// NumberValueIn will only pass if the number literal is within min and max, both inclusive.
+func (q *Query) NumberValueIn(min, max float64) *Query {
+	return q.predicate("NumberValueIn", func(e ast.Node) error {
+		literal, isNumber := e.(*ast.NumberLiteral)
+		if !isNumber {
+			return mismatch(e, "NumberLiteral", "Expression is not a number literal, was %T", e)
+		}
+
+		var value float64
+		switch v := literal.Value.(type) {
+		case int64:
+			value = float64(v)
+		case float64:
+			value = v
+		default:
+			return mismatch(e, "", "Number literal has unsupported value %T", literal.Value)
+		}
+
+		if value < min || value > max {
+			return mismatch(e, "", "Number %v is not within %v and %v", value, min, max)
+		}
+
+		return nil
+	})
+}
+
+// BooleanIs will only pass if the boolean literal has the given value.
+func (q *Query) BooleanIs(value bool) *Query {
+	return q.predicate("BooleanIs", func(e ast.Node) error {
+		literal, isBoolean := e.(*ast.BooleanLiteral)
+		if !isBoolean {
+			return mismatch(e, "BooleanLiteral", "Expression is not a boolean literal, was %T", e)
+		}
+
+		if literal.Value != value {
+			return mismatch(e, "", "Boolean literal is not %v", value)
+		}
+
+		return nil
+	})
+}
+
+// regExp returns the regular expression literal.
+func regExp(e ast.Node) (*ast.RegExpLiteral, error) {
+	literal, isRegExp := e.(*ast.RegExpLiteral)
+	if !isRegExp {
+		return nil, mismatch(e, "RegExpLiteral", "Expression is not a regular expression literal, was %T", e)
+	}
+
+	return literal, nil
+}
+
+// RegExpPatternIs will only pass if the regular expression literal has the given pattern, e.g. `a+` for /a+/g.
+func (q *Query) RegExpPatternIs(pattern string) *Query {
+	return q.predicate("RegExpPatternIs", func(e ast.Node) error {
+		literal, err := regExp(e)
+		if err != nil {
+			return err
+		}
+
+		if literal.Pattern != pattern {
+			return mismatch(e, "", "Invalid pattern for regular expression literal, %v", literal.Pattern)
+		}
+
+		return nil
+	})
+}
+
+// RegExpPatternMatches will only pass if the pattern of the regular expression literal matches the given pattern.
+func (q *Query) RegExpPatternMatches(pattern *regexp.Regexp) *Query {
+	return q.predicate("RegExpPatternMatches", func(e ast.Node) error {
+		literal, err := regExp(e)
+		if err != nil {
+			return err
+		}
+
+		if !pattern.MatchString(literal.Pattern) {
+			return mismatch(e, "", "Regular expression pattern %v does not match %v", literal.Pattern, pattern)
+		}
+
+		return nil
+	})
+}
+
+// RegExpHasFlags will only pass if the regular expression literal has all of the given flags, e.g. "gi".
+func (q *Query) RegExpHasFlags(flags string) *Query {
+	return q.predicate("RegExpHasFlags", func(e ast.Node) error {
+		literal, err := regExp(e)
+		if err != nil {
+			return err
+		}
+
+		for _, flag := range flags {
+			if !strings.ContainsRune(literal.Flags, flag) {
+				return mismatch(e, "", "Regular expression literal does not have flag %c", flag)
+			}
+		}
+
+		return nil
+	})
+}
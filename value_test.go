@@ -0,0 +1,59 @@
+package astquery
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestValuePredicates(t *testing.T) {
+	tests := []struct {
+		src     string
+		query   *Query
+		matches int
+	}{
+		// Test 0
+		{`eval("a"); f(); eval;`, NewQuery().MustBeCall().Child(NewQuery().NameIs("eval")), 1},
+
+		// Test 1
+		{`var _a = 1, b = 2, _c;`, NewQuery().NameMatches(regexp.MustCompile("^_")), 2},
+
+		// Test 2
+		{`f(function g() {}); h(function() {});`, NewQuery().AnyOf(NewQuery().MustBeFunctionLiteral().NameIs("g")), 1},
+
+		// Test 3
+		{`"use strict"; "x";`, NewQuery().StringValueIs("use strict", "y"), 1},
+
+		// Test 4
+		{`"abc"; "bcd"; 1;`, NewQuery().StringValueMatches(regexp.MustCompile("^a")), 1},
+
+		// Test 5
+		{`1; 5; 10.5; 11; "5";`, NewQuery().NumberValueIn(5, 10.5), 2},
+
+		// Test 6
+		{`true; false; 1;`, NewQuery().BooleanIs(false), 1},
+
+		// Test 7
+		{`/a+/g; /b/; "a+";`, NewQuery().RegExpPatternIs("a+"), 1},
+
+		// Test 8
+		{`/a+/g; /b/;`, NewQuery().RegExpPatternMatches(regexp.MustCompile(`\+`)), 1},
+
+		// Test 9
+		{`/a/gi; /b/g; /c/;`, NewQuery().RegExpHasFlags("g"), 2},
+
+		// Test 10
+		{`/a/gi; /b/g; /c/;`, NewQuery().RegExpHasFlags("ig"), 1},
+	}
+
+	for i, test := range tests {
+		matches, err := QuerySource(test.src, test.query)
+		if err != nil {
+			t.Errorf("Test %v failed, %v", i, err)
+			continue
+		}
+
+		if len(matches) != test.matches {
+			t.Errorf("Test %v should have %v matches, got %v", i, test.matches, len(matches))
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package astquery
+
+import (
+	"github.com/robertkrimen/otto/ast"
+)
+
+// children returns the direct child nodes of a node in source order.
+func children(node ast.Node) []ast.Node {
+	var nodes []ast.Node
+	add := func(n ast.Node) {
+		if n != nil {
+			nodes = append(nodes, n)
+		}
+	}
+	addExpression := func(e ast.Expression) {
+		if e != nil {
+			nodes = append(nodes, e)
+		}
+	}
+	addStatement := func(s ast.Statement) {
+		if s != nil {
+			nodes = append(nodes, s)
+		}
+	}
+	addIdentifier := func(i *ast.Identifier) {
+		if i != nil {
+			nodes = append(nodes, i)
+		}
+	}
+
+	switch n := node.(type) {
+	case *ast.Program:
+		for _, s := range n.Body {
+			addStatement(s)
+		}
+
+	// Expressions
+	case *ast.ArrayLiteral:
+		for _, e := range n.Value {
+			addExpression(e)
+		}
+	case *ast.AssignExpression:
+		addExpression(n.Left)
+		addExpression(n.Right)
+	case *ast.BinaryExpression:
+		addExpression(n.Left)
+		addExpression(n.Right)
+	case *ast.BracketExpression:
+		addExpression(n.Left)
+		addExpression(n.Member)
+	case *ast.CallExpression:
+		addExpression(n.Callee)
+		for _, e := range n.ArgumentList {
+			addExpression(e)
+		}
+	case *ast.ConditionalExpression:
+		addExpression(n.Test)
+		addExpression(n.Consequent)
+		addExpression(n.Alternate)
+	case *ast.DotExpression:
+		addExpression(n.Left)
+		addIdentifier(n.Identifier)
+	case *ast.FunctionLiteral:
+		addIdentifier(n.Name)
+		if n.ParameterList != nil {
+			for _, i := range n.ParameterList.List {
+				addIdentifier(i)
+			}
+		}
+		addStatement(n.Body)
+	case *ast.NewExpression:
+		addExpression(n.Callee)
+		for _, e := range n.ArgumentList {
+			addExpression(e)
+		}
+	case *ast.ObjectLiteral:
+		for _, p := range n.Value {
+			addExpression(p.Value)
+		}
+	case *ast.SequenceExpression:
+		for _, e := range n.Sequence {
+			addExpression(e)
+		}
+	case *ast.UnaryExpression:
+		addExpression(n.Operand)
+	case *ast.VariableExpression:
+		addExpression(n.Initializer)
+
+	// Statements
+	case *ast.BlockStatement:
+		for _, s := range n.List {
+			addStatement(s)
+		}
+	case *ast.BranchStatement:
+		addIdentifier(n.Label)
+	case *ast.CaseStatement:
+		addExpression(n.Test)
+		for _, s := range n.Consequent {
+			addStatement(s)
+		}
+	case *ast.CatchStatement:
+		addIdentifier(n.Parameter)
+		addStatement(n.Body)
+	case *ast.DoWhileStatement:
+		addStatement(n.Body)
+		addExpression(n.Test)
+	case *ast.ExpressionStatement:
+		addExpression(n.Expression)
+	case *ast.ForInStatement:
+		addExpression(n.Into)
+		addExpression(n.Source)
+		addStatement(n.Body)
+	case *ast.ForStatement:
+		addExpression(n.Initializer)
+		addExpression(n.Test)
+		addExpression(n.Update)
+		addStatement(n.Body)
+	case *ast.FunctionStatement:
+		if n.Function != nil {
+			add(n.Function)
+		}
+	case *ast.IfStatement:
+		addExpression(n.Test)
+		addStatement(n.Consequent)
+		addStatement(n.Alternate)
+	case *ast.LabelledStatement:
+		addIdentifier(n.Label)
+		addStatement(n.Statement)
+	case *ast.ReturnStatement:
+		addExpression(n.Argument)
+	case *ast.SwitchStatement:
+		addExpression(n.Discriminant)
+		for _, c := range n.Body {
+			if c != nil {
+				add(c)
+			}
+		}
+	case *ast.ThrowStatement:
+		addExpression(n.Argument)
+	case *ast.TryStatement:
+		addStatement(n.Body)
+		if n.Catch != nil {
+			add(n.Catch)
+		}
+		addStatement(n.Finally)
+	case *ast.VariableStatement:
+		for _, e := range n.List {
+			addExpression(e)
+		}
+	case *ast.WhileStatement:
+		addExpression(n.Test)
+		addStatement(n.Body)
+	case *ast.WithStatement:
+		addExpression(n.Object)
+		addStatement(n.Body)
+	}
+
+	return nodes
+}
+
+// walk visits node and every node below it, depth first and in source order.
+// The path holds the ancestors of the visited node, starting from the root.
+// If visit returns false the children of the node are skipped.
+func walk(node ast.Node, visit func(node ast.Node, path []ast.Node) bool) {
+	walkPath(node, nil, visit)
+}
+
+func walkPath(node ast.Node, path []ast.Node, visit func(ast.Node, []ast.Node) bool) {
+	if !visit(node, path) {
+		return
+	}
+
+	path = append(path, node)
+	for _, child := range children(node) {
+		walkPath(child, path, visit)
+	}
+}